@@ -0,0 +1,380 @@
+package etcdcron
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Calendar is a business calendar: the days which are neither weekend days nor
+// holidays are business days. The holidays are dates, without time zone: a
+// time is on a holiday if its date in its own location is one.
+type Calendar struct {
+	// Weekend are the days of week which are not business days.
+	Weekend []time.Weekday
+
+	holidays  map[civilDate]bool
+	recurring []recurringHoliday
+}
+
+// civilDate is a date without time zone.
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) civilDate {
+	year, month, day := t.Date()
+	return civilDate{year, month, day}
+}
+
+// recurringHoliday is a holiday repeated by a recurrence rule, e.g. Christmas,
+// lasting the given number of days.
+type recurringHoliday struct {
+	rule *RRuleSchedule
+	days int
+}
+
+// NewCalendar returns a Calendar without holidays, whose weekend days are
+// Saturday and Sunday.
+func NewCalendar() *Calendar {
+	return &Calendar{
+		Weekend:  []time.Weekday{time.Saturday, time.Sunday},
+		holidays: map[civilDate]bool{},
+	}
+}
+
+// AddHoliday adds the date of the given time to the holidays.
+func (c *Calendar) AddHoliday(date time.Time) {
+	if c.holidays == nil {
+		c.holidays = map[civilDate]bool{}
+	}
+	c.holidays[dateOf(date)] = true
+}
+
+// AddRecurringHoliday adds the dates of the activation times of the given rule
+// to the holidays, e.g. "DTSTART:20001225 RRULE:FREQ=YEARLY" for Christmas.
+// The rule is evaluated in floating time, whatever its time zone.
+func (c *Calendar) AddRecurringHoliday(rule *RRuleSchedule) {
+	c.addRecurringHoliday(rule, 1)
+}
+
+func (c *Calendar) addRecurringHoliday(rule *RRuleSchedule, days int) {
+	floating := *rule
+	if floating.Location != nil {
+		naive := func(t time.Time) time.Time {
+			if t.IsZero() {
+				return t
+			}
+			t = t.In(rule.Location)
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+		}
+		floating.Start, floating.Until = naive(floating.Start), naive(floating.Until)
+		floating.Exdates = make([]time.Time, len(rule.Exdates))
+		for i, exdate := range rule.Exdates {
+			floating.Exdates[i] = naive(exdate)
+		}
+		floating.Location = nil
+	}
+	c.recurring = append(c.recurring, recurringHoliday{rule: &floating, days: days})
+}
+
+// IsHoliday reports whether the date of the given time is a holiday.
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	date := dateOf(t)
+	if c.holidays[date] {
+		return true
+	}
+	day := time.Date(date.year, date.month, date.day, 0, 0, 0, 0, time.UTC)
+	for _, holiday := range c.recurring {
+		// The holiday is the first one starting on or after the earliest day
+		// it could start to include the date.
+		start := holiday.rule.Next(day.AddDate(0, 0, 1-holiday.days).Add(-time.Nanosecond))
+		if !start.IsZero() && start.Before(day.AddDate(0, 0, 1)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBusinessDay reports whether the date of the given time is neither a weekend
+// day nor a holiday.
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	for _, weekday := range c.Weekend {
+		if t.Weekday() == weekday {
+			return false
+		}
+	}
+	return !c.IsHoliday(t)
+}
+
+// ReadDates adds the holidays listed in the given reader, one "YYYY-MM-DD" date
+// per line. Empty lines and lines starting with "#" are ignored.
+func (c *Calendar) ReadDates(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", line)
+		if err != nil {
+			return errors.Wrapf(err, "line %d", n)
+		}
+		c.AddHoliday(date)
+	}
+	return errors.Wrap(scanner.Err(), "fail to read the dates")
+}
+
+// ReadICS adds the holidays of the events of the given iCalendar (RFC 5545)
+// data, e.g. an .ics file exported from a calendar application. Each event
+// makes holidays of the dates from its DTSTART to its DTEND (excluded), and is
+// repeated by its RRULE, if any. The events are expected to be all-day events:
+// their times are ignored.
+func (c *Calendar) ReadICS(r io.Reader) error {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return errors.Wrap(err, "fail to read the iCalendar data")
+	}
+
+	var event map[string]string
+	for n, line := range lines {
+		colon := strings.Index(line, ":")
+		if colon == -1 {
+			continue
+		}
+		name, params, value := strings.ToUpper(line[:colon]), "", line[colon+1:]
+		if semicolon := strings.Index(name, ";"); semicolon != -1 {
+			name, params = name[:semicolon], line[semicolon+1:colon]
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			event = map[string]string{}
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if err := c.addICSEvent(event); err != nil {
+				return errors.Wrapf(err, "event ending line %d", n+1)
+			}
+			event = nil
+		case event != nil && (name == "DTSTART" || name == "DTEND" || name == "EXDATE"):
+			if params != "" {
+				name += ";" + params
+			}
+			if previous, ok := event["EXDATE"]; ok && strings.HasPrefix(name, "EXDATE") {
+				// Keep all the excluded dates, in the parameters of the first
+				// EXDATE.
+				value = previous[strings.Index(previous, ":")+1:] + "," + value
+				name = previous[:strings.Index(previous, ":")]
+			}
+			event[strings.SplitN(name, ";", 2)[0]] = name + ":" + value
+		case event != nil && name == "RRULE":
+			event[name] = line
+		}
+	}
+	return nil
+}
+
+// addICSEvent adds the holidays of an event read by ReadICS, given its
+// DTSTART, DTEND, RRULE and EXDATE properties.
+func (c *Calendar) addICSEvent(event map[string]string) error {
+	dtstart, ok := event["DTSTART"]
+	if !ok {
+		return errors.New("missing DTSTART")
+	}
+	start, err := parseICSDate(dtstart)
+	if err != nil {
+		return errors.Wrap(err, "invalid DTSTART")
+	}
+	days := 1
+	if dtend, ok := event["DTEND"]; ok {
+		end, err := parseICSDate(dtend)
+		if err != nil {
+			return errors.Wrap(err, "invalid DTEND")
+		}
+		if d := int(end.Sub(start).Hours() / 24); d > 1 {
+			days = d
+		}
+	}
+
+	if rrule, ok := event["RRULE"]; ok {
+		spec := "DTSTART:" + start.Format("20060102") + " " + rrule
+		if exdate, ok := event["EXDATE"]; ok {
+			spec += " " + exdate
+		}
+		rule, err := ParseRRule(spec)
+		if err != nil {
+			return err
+		}
+		c.addRecurringHoliday(rule, days)
+		return nil
+	}
+	for i := 0; i < days; i++ {
+		c.AddHoliday(start.AddDate(0, 0, i))
+	}
+	return nil
+}
+
+// parseICSDate returns the date of an iCalendar date or date-time property
+// such as "DTSTART;VALUE=DATE:20261225", as midnight UTC.
+func parseICSDate(property string) (time.Time, error) {
+	value := property[strings.Index(property, ":")+1:]
+	if len(value) < len("20060102") {
+		return time.Time{}, errors.Errorf("%q is not a date", value)
+	}
+	return time.Parse("20060102", value[:len("20060102")])
+}
+
+// unfoldICS returns the content lines of iCalendar data, the lines starting
+// with a space or a tab being the continuation of the previous ones.
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// HolidayPolicy tells what a BusinessDaySchedule does with the activation
+// times which are not on a business day.
+type HolidayPolicy int
+
+const (
+	// SkipHolidays drops the activation times.
+	SkipHolidays HolidayPolicy = iota
+	// ShiftForward moves the activation times to the same time of the next
+	// business day.
+	ShiftForward
+	// ShiftBackward moves the activation times to the same time of the
+	// previous business day.
+	ShiftBackward
+)
+
+var holidayPolicyNames = []string{"SkipHolidays", "ShiftForward", "ShiftBackward"}
+
+func (p HolidayPolicy) String() string {
+	if p < SkipHolidays || p > ShiftBackward {
+		return "HolidayPolicy(" + strconv.Itoa(int(p)) + ")"
+	}
+	return holidayPolicyNames[p]
+}
+
+// BusinessDaySchedule applies a business calendar to a schedule: its
+// activation times which are not on a business day are dropped or shifted to
+// a business day, according to the policy. Several activation times shifted to
+// the same time are activated once.
+type BusinessDaySchedule struct {
+	Schedule Schedule
+	Calendar *Calendar
+	Policy   HolidayPolicy
+}
+
+// OnBusinessDays returns a Schedule activated at the activation times of the
+// given schedule on the business days of the calendar, and at the others
+// according to the policy, e.g. for the last business day of each month:
+//
+//	monthEnd, _ := etcdcron.Parse("0 0 18 L * *")
+//	schedule := etcdcron.OnBusinessDays(monthEnd, calendar, etcdcron.ShiftBackward)
+func OnBusinessDays(schedule Schedule, calendar *Calendar, policy HolidayPolicy) *BusinessDaySchedule {
+	return &BusinessDaySchedule{Schedule: schedule, Calendar: calendar, Policy: policy}
+}
+
+// String returns the schedule in the form of the call to OnBusinessDays,
+// without the calendar, e.g. "OnBusinessDays(0 0 18 L * *; ShiftBackward)". It
+// can't be parsed.
+func (b *BusinessDaySchedule) String() string {
+	return "OnBusinessDays(" + scheduleString(b.Schedule) + "; " + b.Policy.String() + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler, but always returns an error:
+// the String of the schedule can't be parsed.
+func (b *BusinessDaySchedule) MarshalText() ([]byte, error) {
+	return nil, unparsableError(b)
+}
+
+// Next returns the next activation time later than the given time, or the zero
+// time if there is none or it couldn't be found in a reasonable number of
+// steps.
+func (b *BusinessDaySchedule) Next(t time.Time) time.Time {
+	switch b.Policy {
+	case ShiftForward:
+		return b.nextShiftedForward(t)
+	case ShiftBackward:
+		return b.nextShiftedBackward(t)
+	default:
+		next := b.Schedule.Next(t)
+		for step := 0; step < maxCombinatorSteps && !next.IsZero(); step++ {
+			if b.Calendar.IsBusinessDay(next) {
+				return next
+			}
+			next = b.Schedule.Next(next)
+		}
+		return time.Time{}
+	}
+}
+
+// nextShiftedForward returns the next activation time with the ShiftForward
+// policy. Since activation times are never shifted backward, only those after
+// the last business day before the given time can be shifted after it.
+func (b *BusinessDaySchedule) nextShiftedForward(t time.Time) time.Time {
+	previous, ok := b.businessDay(truncateDay(t).Add(-time.Nanosecond), -1)
+	if !ok {
+		return time.Time{}
+	}
+
+	var best time.Time
+	activation := b.Schedule.Next(truncateDay(previous).AddDate(0, 0, 1).Add(-time.Nanosecond))
+	for step := 0; step < maxCombinatorSteps && !activation.IsZero(); step++ {
+		if !best.IsZero() && !activation.Before(best) {
+			break
+		}
+		if shifted, ok := b.businessDay(activation, 1); ok && shifted.After(t) && (best.IsZero() || shifted.Before(best)) {
+			best = shifted
+		}
+		activation = b.Schedule.Next(activation)
+	}
+	return best
+}
+
+// nextShiftedBackward returns the next activation time with the ShiftBackward
+// policy. Since activation times are never shifted forward, only those after
+// the given time can be shifted after it, and the activation times are shifted
+// at most to the last business day before them.
+func (b *BusinessDaySchedule) nextShiftedBackward(t time.Time) time.Time {
+	var best time.Time
+	activation := b.Schedule.Next(t)
+	for step := 0; step < maxCombinatorSteps && !activation.IsZero(); step++ {
+		shifted, ok := b.businessDay(activation, -1)
+		if !ok || !best.IsZero() && !truncateDay(shifted).Before(best) {
+			break
+		}
+		if shifted.After(t) && (best.IsZero() || shifted.Before(best)) {
+			best = shifted
+		}
+		activation = b.Schedule.Next(activation)
+	}
+	return best
+}
+
+// businessDay returns the given time if it is on a business day, the same time
+// of the next (direction 1) or previous (direction -1) business day
+// otherwise. It returns false if there is no business day within a year.
+func (b *BusinessDaySchedule) businessDay(t time.Time, direction int) (time.Time, bool) {
+	for days := 0; days <= 366; days++ {
+		day := t.AddDate(0, 0, direction*days)
+		if b.Calendar.IsBusinessDay(day) {
+			return day, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,146 @@
+package etcdcron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testICS = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+SUMMARY:Christmas
+DTSTART;VALUE=DATE:20001225
+DTEND;VALUE=DATE:20001226
+RRULE:FREQ=YEARLY
+EXDATE;VALUE=DATE:20301225
+END:VEVENT
+BEGIN:VEVENT
+SUMMARY:Year-end
+  closing
+DTSTART;VALUE=DATE:20261228
+DTEND;VALUE=DATE:20261231
+END:VEVENT
+END:VCALENDAR
+`
+
+func testCalendar(t *testing.T) *Calendar {
+	t.Helper()
+	cal := NewCalendar()
+	if err := cal.ReadICS(strings.NewReader(testICS)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cal.ReadDates(strings.NewReader("# New Year\n2027-01-01\n\n")); err != nil {
+		t.Fatal(err)
+	}
+	return cal
+}
+
+func TestCalendarBusinessDays(t *testing.T) {
+	cal := testCalendar(t)
+	tests := []struct {
+		date     string
+		holiday  bool
+		business bool
+	}{
+		{"2026-12-24", false, true},
+		{"2026-12-25", true, false},
+		{"2026-12-26", false, false},
+		{"2026-12-28", true, false},
+		{"2026-12-30", true, false},
+		{"2026-12-31", false, true},
+		{"2027-01-01", true, false},
+		{"2027-12-25", true, false},
+		{"2030-12-25", false, true},
+		{"2031-12-25", true, false},
+	}
+
+	for _, c := range tests {
+		date, _ := time.Parse("2006-01-02", c.date)
+		if actual := cal.IsHoliday(date); actual != c.holiday {
+			t.Errorf("%s: expected holiday %v, got %v", c.date, c.holiday, actual)
+		}
+		if actual := cal.IsBusinessDay(date); actual != c.business {
+			t.Errorf("%s: expected business day %v, got %v", c.date, c.business, actual)
+		}
+	}
+
+	// The date is taken in the location of the time.
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cal.IsHoliday(time.Date(2026, 12, 24, 23, 30, 0, 0, time.UTC).In(paris)) {
+		t.Error("expected December 25th in Paris to be a holiday")
+	}
+}
+
+func TestBusinessDayScheduleNext(t *testing.T) {
+	cal := testCalendar(t)
+	daily, err := Parse("0 0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	monthly, err := Parse("0 0 18 1 * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		schedule Schedule
+		policy   HolidayPolicy
+		time     string
+		expected string
+	}{
+		{daily, SkipHolidays, "2026-12-24T08:00:00Z", "2026-12-24T09:00:00Z"},
+		{daily, SkipHolidays, "2026-12-24T09:00:00Z", "2026-12-31T09:00:00Z"},
+		{daily, SkipHolidays, "2026-12-31T09:00:00Z", "2027-01-04T09:00:00Z"},
+
+		// The activation times shifted to the same time are activated once.
+		{daily, ShiftForward, "2026-12-24T09:00:00Z", "2026-12-31T09:00:00Z"},
+		{daily, ShiftForward, "2026-12-30T12:00:00Z", "2026-12-31T09:00:00Z"},
+		{daily, ShiftForward, "2026-12-31T09:00:00Z", "2027-01-04T09:00:00Z"},
+		{daily, ShiftForward, "2027-01-04T09:00:00Z", "2027-01-05T09:00:00Z"},
+
+		{daily, ShiftBackward, "2026-12-24T08:00:00Z", "2026-12-24T09:00:00Z"},
+		{daily, ShiftBackward, "2026-12-24T09:00:00Z", "2026-12-31T09:00:00Z"},
+		{monthly, ShiftBackward, "2026-12-01T18:00:00Z", "2026-12-31T18:00:00Z"},
+		{monthly, ShiftBackward, "2026-12-31T18:00:00Z", "2027-02-01T18:00:00Z"},
+		{monthly, ShiftForward, "2026-12-01T18:00:00Z", "2027-01-04T18:00:00Z"},
+		{monthly, SkipHolidays, "2026-12-01T18:00:00Z", "2027-02-01T18:00:00Z"},
+	}
+
+	for _, c := range tests {
+		start, _ := time.Parse(time.RFC3339, c.time)
+		expected, _ := time.Parse(time.RFC3339, c.expected)
+		actual := OnBusinessDays(c.schedule, cal, c.policy).Next(start)
+		if !actual.Equal(expected) {
+			t.Errorf("%d %s: (expected) %v != %v (actual)", c.policy, c.time, expected, actual)
+		}
+	}
+
+	// Without business days, the schedule is never activated.
+	never := &Calendar{Weekend: []time.Weekday{0, 1, 2, 3, 4, 5, 6}}
+	for _, policy := range []HolidayPolicy{SkipHolidays, ShiftForward, ShiftBackward} {
+		if actual := OnBusinessDays(daily, never, policy).Next(time.Now()); !actual.IsZero() {
+			t.Errorf("%d: expected the zero time, got %v", policy, actual)
+		}
+	}
+
+	if actual, expected := OnBusinessDays(monthly, cal, ShiftBackward).String(), "OnBusinessDays(0 0 18 1 * *; ShiftBackward)"; actual != expected {
+		t.Errorf("(expected) %q != %q (actual)", expected, actual)
+	}
+}
+
+func TestCalendarReadErrors(t *testing.T) {
+	c := NewCalendar()
+	if err := c.ReadDates(strings.NewReader("2026-12-25\nChristmas\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error on line 2, got %v", err)
+	}
+	if err := c.ReadICS(strings.NewReader("BEGIN:VEVENT\nSUMMARY:Nothing\nEND:VEVENT\n")); err == nil {
+		t.Error("expected an error for an event without DTSTART")
+	}
+	if err := c.ReadICS(strings.NewReader("BEGIN:VEVENT\nDTSTART:20261225\nRRULE:FREQ=SOMETIMES\nEND:VEVENT\n")); err == nil {
+		t.Error("expected an error for an invalid RRULE")
+	}
+}
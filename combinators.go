@@ -0,0 +1,268 @@
+package etcdcron
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxCombinatorSteps bounds the number of activation times a combinator
+// examines in a single call to Next, so that a combination which is never
+// activated, such as the intersection of disjoint schedules, doesn't loop
+// forever.
+const maxCombinatorSteps = 10000
+
+// Window is a recurring period of time, which opens at each activation time
+// of Start and lasts Duration, e.g. "between 23:00 and 01:00 on Sundays":
+//
+//	sundayNight, _ := etcdcron.Parse("0 0 23 * * 0")
+//	window := etcdcron.Window{Start: sundayNight, Duration: 2 * time.Hour}
+type Window struct {
+	Start    Schedule
+	Duration time.Duration
+}
+
+// Contains reports whether the given time is in the window: at or after one of
+// its openings, and before the corresponding end.
+func (w Window) Contains(t time.Time) bool {
+	start := w.Start.Next(t.Add(-w.Duration))
+	return !start.IsZero() && !start.After(t)
+}
+
+// String returns the window in the form of its literal, e.g.
+// "Window(0 0 23 * * 0; 2h0m0s)".
+func (w Window) String() string {
+	return "Window(" + scheduleString(w.Start) + "; " + w.Duration.String() + ")"
+}
+
+// end returns the end of the window containing the given time.
+func (w Window) end(t time.Time) time.Time {
+	return w.Start.Next(t.Add(-w.Duration)).Add(w.Duration)
+}
+
+// UnionSchedule is activated at the activation times of any of its schedules.
+type UnionSchedule struct {
+	Schedules []Schedule
+}
+
+// Union returns a Schedule activated at the activation times of any of the
+// given schedules.
+func Union(schedules ...Schedule) *UnionSchedule {
+	return &UnionSchedule{Schedules: schedules}
+}
+
+// Next returns the earliest of the next activation times of the schedules, or
+// the zero time if none of them is activated anymore.
+func (u *UnionSchedule) Next(t time.Time) time.Time {
+	var next time.Time
+	for _, schedule := range u.Schedules {
+		n := schedule.Next(t)
+		if !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next
+}
+
+// String returns the schedule in the form of the call to Union, e.g.
+// "Union(0 0 12 * * *; 0 30 8 * * *)". It can't be parsed.
+func (u *UnionSchedule) String() string {
+	return "Union(" + schedulesString(u.Schedules) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler, but always returns an error:
+// the String of the schedule can't be parsed.
+func (u *UnionSchedule) MarshalText() ([]byte, error) {
+	return nil, unparsableError(u)
+}
+
+// IntersectSchedule is activated at the times when all its schedules are
+// activated.
+type IntersectSchedule struct {
+	Schedules []Schedule
+}
+
+// Intersect returns a Schedule activated at the times when all the given
+// schedules are activated, e.g. the intersection of "every 10 minutes" and
+// "every 15 minutes" is "every 30 minutes".
+func Intersect(schedules ...Schedule) *IntersectSchedule {
+	return &IntersectSchedule{Schedules: schedules}
+}
+
+// Next returns the next time all the schedules are activated, or the zero time
+// if there is none or it couldn't be found in a reasonable number of steps.
+func (i *IntersectSchedule) Next(t time.Time) time.Time {
+	if len(i.Schedules) == 0 {
+		return time.Time{}
+	}
+	next := i.Schedules[0].Next(t)
+	for step := 0; step < maxCombinatorSteps && !next.IsZero(); step++ {
+		// Every schedule is asked for its first activation at or after the
+		// candidate, which is the next candidate if they don't all agree.
+		agree := true
+		for _, schedule := range i.Schedules {
+			n := schedule.Next(next.Add(-time.Nanosecond))
+			if n.IsZero() {
+				return time.Time{}
+			}
+			if !n.Equal(next) {
+				agree = false
+				if n.After(next) {
+					next = n
+				}
+			}
+		}
+		if agree {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// String returns the schedule in the form of the call to Intersect, e.g.
+// "Intersect(0 */10 * * * *; 0 */15 * * * *)". It can't be parsed.
+func (i *IntersectSchedule) String() string {
+	return "Intersect(" + schedulesString(i.Schedules) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler, but always returns an error:
+// the String of the schedule can't be parsed.
+func (i *IntersectSchedule) MarshalText() ([]byte, error) {
+	return nil, unparsableError(i)
+}
+
+// ExceptSchedule is activated at the activation times of its base schedule
+// which are not in any of its blackout windows.
+type ExceptSchedule struct {
+	Base      Schedule
+	Blackouts []Window
+}
+
+// Except returns a Schedule activated at the activation times of base which
+// are not in any of the blackout windows, e.g. "every 10 minutes except
+// between 23:00 and 01:00 on Sundays":
+//
+//	everyTenMinutes, _ := etcdcron.Parse("0 */10 * * * *")
+//	sundayNight, _ := etcdcron.Parse("0 0 23 * * 0")
+//	schedule := etcdcron.Except(everyTenMinutes, etcdcron.Window{Start: sundayNight, Duration: 2 * time.Hour})
+func Except(base Schedule, blackouts ...Window) *ExceptSchedule {
+	return &ExceptSchedule{Base: base, Blackouts: blackouts}
+}
+
+// Next returns the next activation time of the base schedule which is not in a
+// blackout window, or the zero time if there is none or it couldn't be found
+// in a reasonable number of steps.
+func (e *ExceptSchedule) Next(t time.Time) time.Time {
+	next := e.Base.Next(t)
+	for step := 0; step < maxCombinatorSteps && !next.IsZero(); step++ {
+		blackedOut := false
+		for _, w := range e.Blackouts {
+			if w.Contains(next) {
+				// Skip to the first activation time after the window.
+				blackedOut = true
+				next = e.Base.Next(w.end(next).Add(-time.Nanosecond))
+				break
+			}
+		}
+		if !blackedOut {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// String returns the schedule in the form of the call to Except, e.g.
+// "Except(0 */10 * * * *; Window(0 0 23 * * 0; 2h0m0s))". It can't be parsed.
+func (e *ExceptSchedule) String() string {
+	return "Except(" + windowsString(e.Base, e.Blackouts) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler, but always returns an error:
+// the String of the schedule can't be parsed.
+func (e *ExceptSchedule) MarshalText() ([]byte, error) {
+	return nil, unparsableError(e)
+}
+
+// WithinSchedule is activated at the activation times of its base schedule
+// which are in any of its windows.
+type WithinSchedule struct {
+	Base    Schedule
+	Windows []Window
+}
+
+// Within returns a Schedule activated at the activation times of base which are
+// in any of the windows, e.g. "every 5 minutes during business hours":
+//
+//	everyFiveMinutes, _ := etcdcron.Parse("0 */5 * * * *")
+//	mornings, _ := etcdcron.Parse("0 0 9 * * 1-5")
+//	schedule := etcdcron.Within(everyFiveMinutes, etcdcron.Window{Start: mornings, Duration: 8 * time.Hour})
+func Within(base Schedule, windows ...Window) *WithinSchedule {
+	return &WithinSchedule{Base: base, Windows: windows}
+}
+
+// Next returns the next activation time of the base schedule which is in a
+// window, or the zero time if there is none or it couldn't be found in a
+// reasonable number of steps.
+func (w *WithinSchedule) Next(t time.Time) time.Time {
+	next := w.Base.Next(t)
+	for step := 0; step < maxCombinatorSteps && !next.IsZero(); step++ {
+		// Skip to the first activation time in the next window if it isn't in
+		// one.
+		var opening time.Time
+		for _, window := range w.Windows {
+			if window.Contains(next) {
+				return next
+			}
+			o := window.Start.Next(next)
+			if !o.IsZero() && (opening.IsZero() || o.Before(opening)) {
+				opening = o
+			}
+		}
+		if opening.IsZero() {
+			return time.Time{}
+		}
+		next = w.Base.Next(opening.Add(-time.Nanosecond))
+	}
+	return time.Time{}
+}
+
+// String returns the schedule in the form of the call to Within, e.g.
+// "Within(0 */5 * * * *; Window(0 0 9 * * 1-5; 8h0m0s))". It can't be parsed.
+func (w *WithinSchedule) String() string {
+	return "Within(" + windowsString(w.Base, w.Windows) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler, but always returns an error:
+// the String of the schedule can't be parsed.
+func (w *WithinSchedule) MarshalText() ([]byte, error) {
+	return nil, unparsableError(w)
+}
+
+// scheduleString returns the String of the given schedule, or its type if it
+// has none.
+func scheduleString(schedule Schedule) string {
+	if s, ok := schedule.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", schedule)
+}
+
+// schedulesString returns the String of the given schedules, separated by
+// semicolons as the specs may contain commas.
+func schedulesString(schedules []Schedule) string {
+	items := make([]string, len(schedules))
+	for i, schedule := range schedules {
+		items[i] = scheduleString(schedule)
+	}
+	return strings.Join(items, "; ")
+}
+
+// windowsString returns the String of the base schedule followed by the
+// windows, separated by semicolons.
+func windowsString(base Schedule, windows []Window) string {
+	items := []string{scheduleString(base)}
+	for _, w := range windows {
+		items = append(items, w.String())
+	}
+	return strings.Join(items, "; ")
+}
@@ -0,0 +1,119 @@
+package etcdcron
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, spec string) Schedule {
+	t.Helper()
+	s, err := Parse(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCombinatorsNext(t *testing.T) {
+	sundayNight := Window{Start: mustParse(t, "0 0 23 * * 0"), Duration: 2 * time.Hour}
+	businessHours := Window{Start: mustParse(t, "0 0 9 * * 1-5"), Duration: 8 * time.Hour}
+	everyTenMinutes := mustParse(t, "0 */10 * * * *")
+
+	tests := []struct {
+		schedule Schedule
+		time     string
+		expected string
+	}{
+		// Union.
+		{Union(mustParse(t, "0 0 12 * * *"), mustParse(t, "0 30 8 * * *")), "Mon Jul 9 00:00 2012", "Mon Jul 9 08:30 2012"},
+		{Union(mustParse(t, "0 0 12 * * *"), mustParse(t, "0 30 8 * * *")), "Mon Jul 9 08:30 2012", "Mon Jul 9 12:00 2012"},
+		{Union(mustParse(t, "0 0 12 * * *"), Once(getTime("Mon Jul 9 10:00 2012"))), "Mon Jul 9 09:00 2012", "Mon Jul 9 10:00 2012"},
+		{Union(mustParse(t, "0 0 12 * * *"), Once(getTime("Mon Jul 9 10:00 2012"))), "Mon Jul 9 10:00 2012", "Mon Jul 9 12:00 2012"},
+		{Union(Once(getTime("Mon Jul 9 10:00 2012"))), "Mon Jul 9 10:00 2012", ""},
+		{Union(), "Mon Jul 9 10:00 2012", ""},
+
+		// Intersection.
+		{Intersect(everyTenMinutes, mustParse(t, "0 */15 * * * *")), "Mon Jul 9 14:01 2012", "Mon Jul 9 14:30 2012"},
+		{Intersect(everyTenMinutes, mustParse(t, "0 */15 * * * *")), "Mon Jul 9 14:30 2012", "Mon Jul 9 15:00 2012"},
+		{Intersect(mustParse(t, "0 0 0 13 * *"), mustParse(t, "0 0 0 * * 5")), "Mon Jul 9 00:00 2012", "Fri Jul 13 00:00 2012"},
+		{Intersect(mustParse(t, "0 0 0 13 * *"), mustParse(t, "0 0 0 * * 5")), "Fri Jul 13 00:00 2012", "Fri Sep 13 00:00 2013"},
+		{Intersect(mustParse(t, "0 0 12 * * *"), mustParse(t, "0 30 12 * * *")), "Mon Jul 9 00:00 2012", ""},
+		{Intersect(), "Mon Jul 9 00:00 2012", ""},
+
+		// Exclusion.
+		{Except(everyTenMinutes, sundayNight), "Sun Jul 15 22:40 2012", "Sun Jul 15 22:50 2012"},
+		{Except(everyTenMinutes, sundayNight), "Sun Jul 15 22:50 2012", "Mon Jul 16 01:00 2012"},
+		{Except(everyTenMinutes, sundayNight), "Mon Jul 16 00:30 2012", "Mon Jul 16 01:00 2012"},
+		{Except(everyTenMinutes, sundayNight), "Mon Jul 16 22:50 2012", "Mon Jul 16 23:00 2012"},
+		{Except(everyTenMinutes, sundayNight, businessHours), "Mon Jul 16 08:50 2012", "Mon Jul 16 17:00 2012"},
+		{Except(everyTenMinutes), "Mon Jul 16 08:50 2012", "Mon Jul 16 09:00 2012"},
+		{Except(mustParse(t, "0 0 0 * * 0"), Window{Start: mustParse(t, "0 0 0 * * *"), Duration: time.Hour}), "Mon Jul 16 08:50 2012", ""},
+
+		// Windows.
+		{Within(everyTenMinutes, businessHours), "Mon Jul 16 08:50 2012", "Mon Jul 16 09:00 2012"},
+		{Within(everyTenMinutes, businessHours), "Mon Jul 16 09:00 2012", "Mon Jul 16 09:10 2012"},
+		{Within(everyTenMinutes, businessHours), "Mon Jul 16 16:50 2012", "Tue Jul 17 09:00 2012"},
+		{Within(everyTenMinutes, businessHours), "Fri Jul 20 16:50 2012", "Mon Jul 23 09:00 2012"},
+		{Within(everyTenMinutes, businessHours, sundayNight), "Fri Jul 20 16:50 2012", "Sun Jul 22 23:00 2012"},
+		{Within(mustParse(t, "0 0 12 * * *"), sundayNight), "Fri Jul 20 16:50 2012", ""},
+		{Within(everyTenMinutes), "Fri Jul 20 16:50 2012", ""},
+
+		// Nested combinators.
+		{Except(Union(mustParse(t, "0 0 12 * * *"), mustParse(t, "0 0 0 * * *")), sundayNight), "Sun Jul 15 13:00 2012", "Mon Jul 16 12:00 2012"},
+	}
+
+	for _, c := range tests {
+		actual := c.schedule.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%T %s: (expected) %v != %v (actual)", c.schedule, c.time, expected, actual)
+		}
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	w := Window{Start: mustParse(t, "0 0 23 * * 0"), Duration: 2 * time.Hour}
+	tests := []struct {
+		time     string
+		expected bool
+	}{
+		{"Sun Jul 15 22:59:59 2012", false},
+		{"Sun Jul 15 23:00 2012", true},
+		{"Mon Jul 16 00:59:59 2012", true},
+		{"Mon Jul 16 01:00 2012", false},
+		{"Mon Jul 16 23:30 2012", false},
+	}
+
+	for _, c := range tests {
+		if actual := w.Contains(getTime(c.time)); actual != c.expected {
+			t.Errorf("%s: (expected) %v != %v (actual)", c.time, c.expected, actual)
+		}
+	}
+}
+
+func TestCombinatorsString(t *testing.T) {
+	sundayNight := Window{Start: mustParse(t, "0 0 23 * * 0"), Duration: 2 * time.Hour}
+	everyTenMinutes := mustParse(t, "0 */10 * * * *")
+
+	tests := []struct {
+		schedule Schedule
+		expected string
+	}{
+		{Union(mustParse(t, "0 0 12 * * *"), Every(time.Hour)), "Union(0 0 12 * * *; @every 1h0m0s)"},
+		{Intersect(everyTenMinutes, mustParse(t, "0 */15 * * * *")), "Intersect(0 */10 * * * *; 0 */15 * * * *)"},
+		{Except(everyTenMinutes, sundayNight), "Except(0 */10 * * * *; Window(0 0 23 * * 0; 2h0m0s))"},
+		{Within(everyTenMinutes), "Within(0 */10 * * * *)"},
+		{Except(Union(), sundayNight), "Except(Union(); Window(0 0 23 * * 0; 2h0m0s))"},
+	}
+
+	for _, c := range tests {
+		if actual := c.schedule.(fmt.Stringer).String(); actual != c.expected {
+			t.Errorf("(expected) %q != %q (actual)", c.expected, actual)
+		}
+		// The combinators are described by their String.
+		if actual, err := Describe(c.schedule, "en"); err != nil || actual != c.expected {
+			t.Errorf("%s: unexpected description %q, %v", c.expected, actual, err)
+		}
+	}
+}
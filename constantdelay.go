@@ -1,27 +1,155 @@
 package etcdcron
 
-import "time"
+import (
+	"math"
+	"time"
+)
+
+// unixEpoch is the anchor of the parsed "@every" schedules.
+var unixEpoch = time.Unix(0, 0).UTC()
 
 // ConstantDelaySchedule represents a simple recurring duty cycle, e.g. "Every 5 minutes".
-// It does not support jobs more frequent than once a second.
+// It does not support jobs more frequent than once a millisecond.
 type ConstantDelaySchedule struct {
 	Delay time.Duration
+	// Anchor, if not zero, aligns the activation times on Anchor plus a
+	// multiple of Delay, so that they don't depend on the time the Cron was
+	// started. With the zero Anchor, the activation times are Delay after the
+	// time given to Next.
+	Anchor time.Time
 }
 
-// Every returns a crontab Schedule that activates once every duration.
-// Delays of less than a second are not supported (will round up to 1 second).
-// Any fields less than a Second are truncated.
+// Every returns a crontab Schedule that activates once every duration, after
+// the time given to Next. Its activation times depend on the time the Cron was
+// started; use EveryFrom to align them.
+// Delays of less than a millisecond are not supported (will round up to 1
+// millisecond). Any fields less than a Millisecond are truncated.
 func Every(duration time.Duration) ConstantDelaySchedule {
-	if duration < time.Second {
-		duration = time.Second
+	if duration < time.Millisecond {
+		duration = time.Millisecond
 	}
 	return ConstantDelaySchedule{
-		Delay: duration - time.Duration(duration.Nanoseconds())%time.Second,
+		Delay: duration.Truncate(time.Millisecond),
 	}
 }
 
-// Next returns the next time this should be run.
-// This rounds so that the next activation time will be on the second.
+// EveryFrom returns a crontab Schedule that activates once every duration,
+// at the anchor plus a multiple of the duration, e.g. at 00:15, 01:15, 02:15…
+// every hour from 00:15. The anchor is truncated to the millisecond.
+func EveryFrom(duration time.Duration, anchor time.Time) ConstantDelaySchedule {
+	schedule := Every(duration)
+	schedule.Anchor = anchor.Truncate(time.Millisecond)
+	return schedule
+}
+
+// Next returns the next time this should be run, or the zero time if Delay
+// isn't positive or if t is too far from Anchor.
+// This rounds so that the next activation time will be on the second, or on
+// the millisecond if Delay isn't a whole number of seconds.
 func (schedule ConstantDelaySchedule) Next(t time.Time) time.Time {
-	return t.Add(schedule.Delay - time.Duration(t.Nanosecond())*time.Nanosecond)
+	if schedule.Delay <= 0 {
+		return time.Time{}
+	}
+	if schedule.Anchor.IsZero() {
+		return schedule.truncate(t).Add(schedule.Delay)
+	}
+	// The first multiple of Delay after t, without multiplying Delay which
+	// may overflow.
+	r, ok := schedule.remainder(t)
+	if !ok {
+		return time.Time{}
+	}
+	return t.Add(-r).Add(schedule.Delay)
+}
+
+// Prev returns the previous time this should have been run, the inverse of
+// Next: Next(Prev(t)) is t for times on the second.
+func (schedule ConstantDelaySchedule) Prev(t time.Time) time.Time {
+	if schedule.Delay <= 0 {
+		return time.Time{}
+	}
+	if schedule.Anchor.IsZero() {
+		return schedule.truncate(t).Add(-schedule.Delay)
+	}
+	// The last multiple of Delay before t.
+	r, ok := schedule.remainder(t)
+	if !ok {
+		return time.Time{}
+	}
+	if r == 0 {
+		r = schedule.Delay
+	}
+	return t.Add(-r)
+}
+
+// remainder returns the duration from the last multiple of Delay after Anchor
+// to t, or false if t is too far from Anchor for the duration between them to
+// be represented.
+func (schedule ConstantDelaySchedule) remainder(t time.Time) (time.Duration, bool) {
+	d := t.Sub(schedule.Anchor)
+	if d == math.MaxInt64 || d == math.MinInt64 {
+		return 0, false
+	}
+	r := d % schedule.Delay
+	if r < 0 {
+		r += schedule.Delay
+	}
+	return r, true
+}
+
+// truncate rounds the given time down to the resolution of Delay: the second,
+// or the millisecond.
+func (schedule ConstantDelaySchedule) truncate(t time.Time) time.Time {
+	resolution := time.Second
+	if schedule.Delay%time.Second != 0 {
+		resolution = time.Millisecond
+	}
+	return t.Add(-time.Duration(t.Nanosecond()) % resolution)
+}
+
+// offset returns the offset of the activation times from the Unix epoch, less
+// than Delay.
+func (schedule ConstantDelaySchedule) offset() time.Duration {
+	if schedule.Anchor.IsZero() || schedule.Delay <= 0 {
+		return 0
+	}
+	offset := schedule.Anchor.Sub(unixEpoch) % schedule.Delay
+	if offset < 0 {
+		offset += schedule.Delay
+	}
+	return offset
+}
+
+// String returns the descriptor of the schedule, e.g. "@every 1h30m0s" or
+// "@every 1h0m0s offset 15m0s". The anchor is given as its offset from the
+// Unix epoch. The zero Anchor is not encoded: the parsed descriptors are always
+// anchored, so only the String of anchored schedules gives them back.
+func (schedule ConstantDelaySchedule) String() string {
+	if offset := schedule.offset(); offset != 0 {
+		return "@every " + schedule.Delay.String() + " offset " + offset.String()
+	}
+	return "@every " + schedule.Delay.String()
+}
+
+// MarshalText implements encoding.TextMarshaler, using String. It returns an
+// error if the Anchor is zero.
+func (schedule ConstantDelaySchedule) MarshalText() ([]byte, error) {
+	if schedule.Anchor.IsZero() {
+		return nil, unparsableError(schedule)
+	}
+	return []byte(schedule.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, using Parse.
+func (schedule *ConstantDelaySchedule) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	delay, ok := parsed.(ConstantDelaySchedule)
+	if !ok {
+		return unexpectedScheduleError(text, parsed)
+	}
+	*schedule = delay
+	return nil
 }
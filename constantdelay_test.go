@@ -34,8 +34,13 @@ func TestConstantDelayNext(t *testing.T) {
 		// Round to nearest second on the delay
 		{"Mon Jul 9 14:45 2012", 15*time.Minute + 50*time.Nanosecond, "Mon Jul 9 15:00 2012"},
 
-		// Round up to 1 second if the duration is less.
-		{"Mon Jul 9 14:45:00 2012", 15 * time.Millisecond, "Mon Jul 9 14:45:01 2012"},
+		// Round up to 1 millisecond if the duration is less.
+		{"Mon Jul 9 14:45:00 2012", 15 * time.Microsecond, "Mon Jul 9 14:45:00.001 2012"},
+
+		// Sub-second delays, rounded to the millisecond.
+		{"Mon Jul 9 14:45:00 2012", 250 * time.Millisecond, "Mon Jul 9 14:45:00.250 2012"},
+		{"Mon Jul 9 14:45:59.875 2012", 250*time.Millisecond + 50*time.Microsecond, "Mon Jul 9 14:46:00.125 2012"},
+		{"Mon Jul 9 14:45:00.0055 2012", 1500 * time.Millisecond, "Mon Jul 9 14:45:01.505 2012"},
 
 		// Round to nearest second when calculating the next time.
 		{"Mon Jul 9 14:45:00.005 2012", 15 * time.Minute, "Mon Jul 9 15:00 2012"},
@@ -52,3 +57,103 @@ func TestConstantDelayNext(t *testing.T) {
 		}
 	}
 }
+
+func TestConstantDelayPrev(t *testing.T) {
+	tests := []struct {
+		time     string
+		delay    time.Duration
+		expected string
+	}{
+		{"Mon Jul 9 15:00 2012", 15 * time.Minute, "Mon Jul 9 14:45 2012"},
+		{"Tue Jul 10 00:20:15 2012", 44*time.Minute + 24*time.Second, "Mon Jul 9 23:35:51 2012"},
+		{"Tue Jan 1 00:00:00 2013", 15 * time.Second, "Mon Dec 31 23:59:45 2012"},
+
+		// Round to nearest second.
+		{"Mon Jul 9 15:00:00.005 2012", 15 * time.Minute, "Mon Jul 9 14:45 2012"},
+	}
+
+	for _, c := range tests {
+		actual := Every(c.delay).Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if actual != expected {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.delay, expected, actual)
+		}
+	}
+}
+
+func TestConstantDelayAnchored(t *testing.T) {
+	anchor := getTime("Mon Jul 9 00:15 2012")
+	tests := []struct {
+		time  string
+		delay time.Duration
+		next  string
+		prev  string
+	}{
+		{"Mon Jul 9 14:45 2012", time.Hour, "Mon Jul 9 15:15 2012", "Mon Jul 9 14:15 2012"},
+		{"Mon Jul 9 14:15 2012", time.Hour, "Mon Jul 9 15:15 2012", "Mon Jul 9 13:15 2012"},
+		{"Mon Jul 9 14:15:00.005 2012", time.Hour, "Mon Jul 9 15:15 2012", "Mon Jul 9 14:15 2012"},
+		{"Mon Jul 9 14:14:59 2012", time.Hour, "Mon Jul 9 14:15 2012", "Mon Jul 9 13:15 2012"},
+
+		{"Mon Jul 9 14:15:00.100 2012", 250 * time.Millisecond, "Mon Jul 9 14:15:00.250 2012", "Mon Jul 9 14:15:00 2012"},
+
+		// Before the anchor.
+		{"Sun Jul 8 23:50 2012", 10 * time.Minute, "Sun Jul 8 23:55 2012", "Sun Jul 8 23:45 2012"},
+		{"Sun Jul 8 23:45 2012", 10 * time.Minute, "Sun Jul 8 23:55 2012", "Sun Jul 8 23:35 2012"},
+		{"Mon Jul 2 00:15 2012", 7 * 24 * time.Hour, "Mon Jul 9 00:15 2012", "Mon Jun 25 00:15 2012"},
+	}
+
+	for _, c := range tests {
+		schedule := EveryFrom(c.delay, anchor)
+		if actual, expected := schedule.Next(getTime(c.time)), getTime(c.next); !actual.Equal(expected) {
+			t.Errorf("Next %s, \"%s\": (expected) %v != %v (actual)", c.time, c.delay, expected, actual)
+		}
+		if actual, expected := schedule.Prev(getTime(c.time)), getTime(c.prev); !actual.Equal(expected) {
+			t.Errorf("Prev %s, \"%s\": (expected) %v != %v (actual)", c.time, c.delay, expected, actual)
+		}
+	}
+
+	// Parsed schedules give the same activation times whatever the time they
+	// are started at.
+	parsed, err := Parse("@every 1h30m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := getTime("Mon Jul 9 14:45 2012")
+	for _, d := range []time.Duration{0, 7 * time.Second, 14 * time.Minute} {
+		if actual, expected := parsed.Next(start.Add(d)), getTime("Mon Jul 9 15:00 2012"); !actual.Equal(expected) {
+			t.Errorf("Next %v: (expected) %v != %v (actual)", start.Add(d), expected, actual)
+		}
+	}
+}
+
+func TestConstantDelayLimits(t *testing.T) {
+	start := getTime("Mon Jul 9 14:45 2012")
+
+	// Without a positive delay, there is no activation time.
+	for _, schedule := range []ConstantDelaySchedule{{}, {Anchor: unixEpoch}, {Delay: -time.Hour, Anchor: unixEpoch}} {
+		if next, prev := schedule.Next(start), schedule.Prev(start); !next.IsZero() || !prev.IsZero() {
+			t.Errorf("%+v: (expected) zero times != %v and %v (actual)", schedule, next, prev)
+		}
+	}
+
+	// The multiples of the longest delays overflow a time.Duration: the times
+	// more than a time.Duration away from the anchor have no activation time.
+	parsed, err := Parse("@every 2562047h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule := parsed.(ConstantDelaySchedule)
+	first, second := unixEpoch.Add(schedule.Delay), unixEpoch.Add(schedule.Delay).Add(schedule.Delay)
+	for _, c := range []struct{ time, next, prev time.Time }{
+		{start, first, unixEpoch},
+		{first, second, unixEpoch},
+		{second, time.Time{}, time.Time{}},
+	} {
+		if next := schedule.Next(c.time); !next.Equal(c.next) {
+			t.Errorf("Next %v: (expected) %v != %v (actual)", c.time, c.next, next)
+		}
+		if prev := schedule.Prev(c.time); !prev.Equal(c.prev) {
+			t.Errorf("Prev %v: (expected) %v != %v (actual)", c.time, c.prev, prev)
+		}
+	}
+}
@@ -32,6 +32,8 @@ type Cron struct {
 	funcCtx           func(context.Context, Job) context.Context
 	running           bool
 	etcdclient        EtcdMutexBuilder
+	location          *time.Location
+	parser            *Parser
 }
 
 // Job contains 3 mandatory options to define a job
@@ -42,6 +44,13 @@ type Job struct {
 	Rhythm string
 	// Routine method
 	Func func(context.Context) error
+	// Time zone in which the rhythm is evaluated, overriding the one of the
+	// Cron (optional)
+	Location *time.Location
+	// Maximum delay added to each activation time, to spread the load of the
+	// jobs. The delays are derived from the name of the job, see Jitter
+	// (optional)
+	Jitter time.Duration
 }
 
 func (j Job) Run(ctx context.Context) error {
@@ -74,7 +83,8 @@ type Entry struct {
 	Schedule Schedule
 
 	// The next time the job will run. This is the zero time if Cron has not been
-	// started or this entry's schedule is unsatisfiable
+	// started. Once started, the entries whose schedule is exhausted or
+	// unsatisfiable are removed from the Cron.
 	Next time.Time
 
 	// The last time this job was run. This is the zero time if the job has never
@@ -130,6 +140,24 @@ func WithFuncCtx(f func(context.Context, Job) context.Context) CronOpt {
 	})
 }
 
+// WithLocation sets the time zone in which the jobs schedules are evaluated.
+// It defaults to the local time zone of the host. Every node of a cluster
+// should use the same location, otherwise they won't agree on the activation
+// times of the jobs.
+func WithLocation(loc *time.Location) CronOpt {
+	return CronOpt(func(cron *Cron) {
+		cron.location = loc
+	})
+}
+
+// WithParser sets the parser of the rhythms of the jobs added with AddJob. It
+// defaults to the one of Parse.
+func WithParser(p *Parser) CronOpt {
+	return CronOpt(func(cron *Cron) {
+		cron.parser = p
+	})
+}
+
 // New returns a new Cron job runner.
 func New(opts ...CronOpt) (*Cron, error) {
 	cron := &Cron{
@@ -161,12 +189,18 @@ func New(opts ...CronOpt) (*Cron, error) {
 			log.Printf("[etcd-cron] error when handling '%v' job: %v", j.Name, err)
 		}
 	}
+	if cron.location == nil {
+		cron.location = time.Local
+	}
+	if cron.parser == nil {
+		cron.parser = defaultParser
+	}
 	return cron, nil
 }
 
 // AddFunc adds a Job to the Cron to be run on the given schedule.
 func (c *Cron) AddJob(job Job) error {
-	schedule, err := Parse(job.Rhythm)
+	schedule, err := c.parser.ParseHashed(job.Rhythm, job.canonicalName())
 	if err != nil {
 		return err
 	}
@@ -174,8 +208,21 @@ func (c *Cron) AddJob(job Job) error {
 	return nil
 }
 
-// Schedule adds a Job to the Cron to be run on the given schedule.
+// Schedule adds a Job to the Cron to be run on the given schedule, delayed
+// according to the Jitter of the job. The JitterSchedules and RandomSchedules
+// without key, even within combined schedules, are keyed by the name of the
+// job, as the ones parsed by AddJob. The maximum
+// delay of a JitterSchedule given as is is replaced by the Jitter of the job,
+// if any, rather than delayed twice.
 func (c *Cron) Schedule(schedule Schedule, job Job) {
+	schedule, _ = keySchedule(schedule, job.canonicalName())
+	if j, ok := schedule.(*JitterSchedule); ok && job.Jitter > 0 {
+		replaced := *j
+		replaced.Max = job.Jitter
+		schedule = &replaced
+	} else if job.Jitter > 0 {
+		schedule = &JitterSchedule{Schedule: schedule, Max: job.Jitter, Key: job.canonicalName()}
+	}
 	entry := &Entry{
 		Schedule: schedule,
 		Job:      job,
@@ -188,6 +235,85 @@ func (c *Cron) Schedule(schedule Schedule, job Job) {
 	c.add <- entry
 }
 
+// keySchedule returns the given schedule with the given key set on the
+// JitterSchedules and RandomSchedules without key it is made of, and true if
+// there are some. The
+// schedules are copied rather than modified.
+func keySchedule(schedule Schedule, key string) (Schedule, bool) {
+	switch s := schedule.(type) {
+	case *JitterSchedule:
+		keyed := *s
+		keyed.Schedule, _ = keySchedule(s.Schedule, key)
+		if keyed.Key == "" {
+			keyed.Key = key
+		}
+		return &keyed, true
+	case *RandomSchedule:
+		if s.Key == "" {
+			keyed := *s
+			keyed.Key = key
+			return &keyed, true
+		}
+	case *UnionSchedule:
+		if schedules, ok := keySchedules(s.Schedules, key); ok {
+			return &UnionSchedule{Schedules: schedules}, true
+		}
+	case *IntersectSchedule:
+		if schedules, ok := keySchedules(s.Schedules, key); ok {
+			return &IntersectSchedule{Schedules: schedules}, true
+		}
+	case *ExceptSchedule:
+		base, baseKeyed := keySchedule(s.Base, key)
+		if blackouts, ok := keyWindows(s.Blackouts, key); ok || baseKeyed {
+			return &ExceptSchedule{Base: base, Blackouts: blackouts}, true
+		}
+	case *WithinSchedule:
+		base, baseKeyed := keySchedule(s.Base, key)
+		if windows, ok := keyWindows(s.Windows, key); ok || baseKeyed {
+			return &WithinSchedule{Base: base, Windows: windows}, true
+		}
+	case *PeriodSchedule:
+		if wrapped, ok := keySchedule(s.Schedule, key); ok {
+			keyed := *s
+			keyed.Schedule = wrapped
+			return &keyed, true
+		}
+	case *BusinessDaySchedule:
+		if wrapped, ok := keySchedule(s.Schedule, key); ok {
+			keyed := *s
+			keyed.Schedule = wrapped
+			return &keyed, true
+		}
+	}
+	return schedule, false
+}
+
+// keySchedules calls keySchedule on each of the given schedules.
+func keySchedules(schedules []Schedule, key string) ([]Schedule, bool) {
+	result := make([]Schedule, len(schedules))
+	keyed := false
+	for i, schedule := range schedules {
+		var ok bool
+		result[i], ok = keySchedule(schedule, key)
+		keyed = keyed || ok
+	}
+	return result, keyed
+}
+
+// keyWindows calls keySchedule on the start schedule of each of the given
+// windows.
+func keyWindows(windows []Window, key string) ([]Window, bool) {
+	result := make([]Window, len(windows))
+	keyed := false
+	for i, w := range windows {
+		var ok bool
+		result[i] = w
+		result[i].Start, ok = keySchedule(w.Start, key)
+		keyed = keyed || ok
+	}
+	return result, keyed
+}
+
 // Entries returns a snapshot of the cron entries.
 func (c *Cron) Entries() []*Entry {
 	if c.running {
@@ -208,17 +334,24 @@ func (c *Cron) Start(ctx context.Context) {
 // access to the 'running' state variable.
 func (c *Cron) run(ctx context.Context) {
 	// Figure out the next activation times for each entry.
-	now := time.Now().Local()
+	now := time.Now().In(c.location)
 	for _, entry := range c.entries {
-		entry.Next = entry.Schedule.Next(now)
+		entry.Next = entry.Schedule.Next(now.In(c.jobLocation(entry.Job)))
+		c.checkNext(ctx, entry, now)
 	}
 
 	for {
 		// Determine the next entry to run.
 		sort.Sort(byTime(c.entries))
 
+		// Drop the entries whose schedule is exhausted, they won't run anymore.
+		for len(c.entries) > 0 && c.entries[len(c.entries)-1].Next.IsZero() {
+			c.entries[len(c.entries)-1] = nil
+			c.entries = c.entries[:len(c.entries)-1]
+		}
+
 		var effective time.Time
-		if len(c.entries) == 0 || c.entries[0].Next.IsZero() {
+		if len(c.entries) == 0 {
 			// If there are no entries yet, just sleep - it still handles new entries
 			// and stop requests.
 			effective = now.AddDate(10, 0, 0)
@@ -230,11 +363,11 @@ func (c *Cron) run(ctx context.Context) {
 		case now = <-time.After(effective.Sub(now)):
 			// Run every entry whose next time was this effective time.
 			for _, e := range c.entries {
-				if e.Next != effective {
+				if !e.Next.Equal(effective) {
 					break
 				}
 				e.Prev = e.Next
-				e.Next = e.Schedule.Next(effective)
+				e.Next = e.Schedule.Next(effective.In(c.jobLocation(e.Job)))
 
 				go func(ctx context.Context, e *Entry) {
 					defer func() {
@@ -253,7 +386,7 @@ func (c *Cron) run(ctx context.Context) {
 						ctx = c.funcCtx(ctx, e.Job)
 					}
 
-					m, err := c.etcdclient.NewMutex(fmt.Sprintf("etcd_cron/%s/%d", e.Job.canonicalName(), effective.Unix()))
+					m, err := c.etcdclient.NewMutex(lockName(e.Job, effective))
 					if err != nil {
 						go c.etcdErrorsHandler(ctx, e.Job, errors.Wrapf(err, "fail to create etcd mutex for job '%v'", e.Job.Name))
 						return
@@ -280,7 +413,8 @@ func (c *Cron) run(ctx context.Context) {
 
 		case newEntry := <-c.add:
 			c.entries = append(c.entries, newEntry)
-			newEntry.Next = newEntry.Schedule.Next(now)
+			newEntry.Next = newEntry.Schedule.Next(now.In(c.jobLocation(newEntry.Job)))
+			c.checkNext(ctx, newEntry, now)
 
 		case <-c.snapshot:
 			c.snapshot <- c.entrySnapshot()
@@ -290,8 +424,37 @@ func (c *Cron) run(ctx context.Context) {
 		}
 
 		// 'now' should be updated after newEntry and snapshot cases.
-		now = time.Now().Local()
+		now = time.Now().In(c.location)
+	}
+}
+
+// checkNext reports the entries which are not activated after the given time
+// to the errors handler: they are dropped and never run.
+func (c *Cron) checkNext(ctx context.Context, entry *Entry, now time.Time) {
+	if entry.Next.IsZero() {
+		go c.errorsHandler(ctx, entry.Job, errors.Wrapf(ErrUnsatisfiable, "schedule of job '%v' is not activated after %v", entry.Job.Name, now))
+	}
+}
+
+// lockName returns the name of the etcd mutex of the run of the given job at
+// the given activation time: "etcd_cron/<job>/<unix time>". The milliseconds
+// are only given for the activation times which aren't on the second, e.g.
+// "etcd_cron/poll/1781000000.250", so that the names of the whole-second
+// schedules don't change.
+func lockName(job Job, effective time.Time) string {
+	if ms := effective.Nanosecond() / int(time.Millisecond); ms != 0 {
+		return fmt.Sprintf("etcd_cron/%s/%d.%03d", job.canonicalName(), effective.Unix(), ms)
+	}
+	return fmt.Sprintf("etcd_cron/%s/%d", job.canonicalName(), effective.Unix())
+}
+
+// jobLocation returns the time zone in which the schedule of the given job is
+// evaluated.
+func (c *Cron) jobLocation(job Job) *time.Location {
+	if job.Location != nil {
+		return job.Location
 	}
+	return c.location
 }
 
 // Stop the cron scheduler.
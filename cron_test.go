@@ -2,8 +2,10 @@ package etcdcron
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -291,6 +293,292 @@ func TestLocalTimezone(t *testing.T) {
 	}
 }
 
+// Test that the cron is run in the time zone it has been configured with.
+func TestNonLocalTimezone(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	loc := nonLocalLocation(t)
+	next := time.Now().In(loc).Add(time.Second)
+	spec := fmt.Sprintf("%d %d %d %d %d ?",
+		next.Second(), next.Minute(), next.Hour(), next.Day(), next.Month())
+
+	cron, err := New(WithLocation(loc))
+	if err != nil {
+		t.Fatal("unexpected error")
+	}
+	cron.AddJob(Job{
+		Name:   "test-non-local",
+		Rhythm: spec,
+		Func: func(context.Context) error {
+			wg.Done()
+			return nil
+		},
+	})
+
+	cron.Start(context.Background())
+	defer cron.Stop()
+
+	select {
+	case <-time.After(ONE_SECOND):
+		t.FailNow()
+	case <-wait(wg):
+	}
+}
+
+// Test that the time zone of a job takes precedence over the one of the cron.
+func TestJobLocation(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	loc := nonLocalLocation(t)
+	next := time.Now().In(loc).Add(time.Second)
+	spec := fmt.Sprintf("%d %d %d %d %d ?",
+		next.Second(), next.Minute(), next.Hour(), next.Day(), next.Month())
+
+	cron, err := New(WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal("unexpected error")
+	}
+	cron.AddJob(Job{
+		Name:     "test-job-location",
+		Rhythm:   spec,
+		Location: loc,
+		Func: func(context.Context) error {
+			wg.Done()
+			return nil
+		},
+	})
+
+	cron.Start(context.Background())
+	defer cron.Stop()
+
+	select {
+	case <-time.After(ONE_SECOND):
+		t.FailNow()
+	case <-wait(wg):
+	}
+}
+
+// Test that the rhythms of the jobs are parsed with the parser of the cron.
+func TestWithParser(t *testing.T) {
+	parser, err := NewParser(WithStandardLayout())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cron, err := New(WithParser(parser))
+	if err != nil {
+		t.Fatal("unexpected error")
+	}
+
+	err = cron.AddJob(Job{
+		Name:   "test-parser-standard",
+		Rhythm: "30 * * * *",
+		Func:   func(context.Context) error { return nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cron.AddJob(Job{
+		Name:   "test-parser-seconds",
+		Rhythm: "0 30 * * * *",
+		Func:   func(context.Context) error { return nil },
+	})
+	if err == nil {
+		t.Error("expected an error adding a job with a seconds field")
+	}
+
+	entries := cron.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("(expected) 1 entry != %d (actual)", len(entries))
+	}
+	schedule := entries[0].Schedule.(*SpecSchedule)
+	if schedule.Second != 1 || schedule.Minute != 1<<30 {
+		t.Errorf("(expected) minute 30 != %v (actual)", schedule)
+	}
+}
+
+// Test that a job with a recurrence rule rhythm runs.
+func TestRRuleJob(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	cron, err := New()
+	if err != nil {
+		t.Fatal("unexpected error")
+	}
+	err = cron.AddJob(Job{
+		Name:   "test-rrule",
+		Rhythm: "RRULE:FREQ=SECONDLY",
+		Func:   func(context.Context) error { wg.Done(); return nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cron.Start(context.Background())
+	defer cron.Stop()
+
+	select {
+	case <-time.After(2 * ONE_SECOND):
+		t.FailNow()
+	case <-wait(wg):
+	}
+}
+
+// Test that a one-shot job runs once, and is then dropped.
+func TestOnceJob(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	cron, err := New()
+	if err != nil {
+		t.Fatal("unexpected error")
+	}
+	err = cron.AddJob(Job{
+		Name:   "test-once",
+		Rhythm: "@at " + time.Now().Add(time.Second).Format(time.RFC3339),
+		Func:   func(context.Context) error { wg.Done(); return nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cron.Start(context.Background())
+	defer cron.Stop()
+
+	select {
+	case <-time.After(2 * ONE_SECOND):
+		t.FailNow()
+	case <-wait(wg):
+	}
+
+	if entries := cron.Entries(); len(entries) != 0 {
+		t.Errorf("(expected) no entries != %d (actual)", len(entries))
+	}
+}
+
+// Test that the jitter of a job is keyed by its name.
+func TestJitterJob(t *testing.T) {
+	cron, err := New()
+	if err != nil {
+		t.Fatal("unexpected error")
+	}
+	err = cron.AddJob(Job{
+		Name:   "Jitter Job",
+		Rhythm: "0 0 0 * * *",
+		Jitter: 30 * time.Second,
+		Func:   func(context.Context) error { return nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := cron.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("(expected) 1 entry != %d (actual)", len(entries))
+	}
+	j, ok := entries[0].Schedule.(*JitterSchedule)
+	if !ok {
+		t.Fatalf("expected a *JitterSchedule, got %T", entries[0].Schedule)
+	}
+	if j.Key != "jitter_job" || j.Max != 30*time.Second {
+		t.Errorf("unexpected jitter key %q or max %v", j.Key, j.Max)
+	}
+
+	// A JitterSchedule without key is keyed by the name of the job.
+	daily, _ := Parse("0 0 0 * * *")
+	cron.Schedule(Jitter(daily, time.Minute), Job{Name: "Other Job", Func: func(context.Context) error { return nil }})
+	entries = cron.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("(expected) 2 entries != %d (actual)", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Job.Name != "Other Job" {
+			continue
+		}
+		if j := entry.Schedule.(*JitterSchedule); j.Key != "other_job" || j.Max != time.Minute {
+			t.Errorf("unexpected jitter key %q or max %v", j.Key, j.Max)
+		}
+	}
+
+	// The Jitter of the job replaces the one of a JitterSchedule.
+	cron.Schedule(Jitter(daily, time.Minute), Job{Name: "Third Job", Jitter: time.Hour, Func: func(context.Context) error { return nil }})
+	for _, entry := range cron.Entries() {
+		if entry.Job.Name != "Third Job" {
+			continue
+		}
+		j := entry.Schedule.(*JitterSchedule)
+		if j.Key != "third_job" || j.Max != time.Hour || j.Schedule != daily {
+			t.Errorf("unexpected jitter key %q, max %v or schedule %v", j.Key, j.Max, j.Schedule)
+		}
+	}
+
+	// The JitterSchedules within combined schedules are keyed too, without
+	// modifying the given schedule.
+	nested := Jitter(daily, time.Minute)
+	window := Window{Start: daily, Duration: time.Hour}
+	cron.Schedule(EveryPeriod(Except(Union(daily, nested), window), Weekly, 2, time.Now()), Job{Name: "Fourth Job", Func: func(context.Context) error { return nil }})
+	for _, entry := range cron.Entries() {
+		if entry.Job.Name != "Fourth Job" {
+			continue
+		}
+		union := entry.Schedule.(*PeriodSchedule).Schedule.(*ExceptSchedule).Base.(*UnionSchedule)
+		if j := union.Schedules[1].(*JitterSchedule); j.Key != "fourth_job" || j.Max != time.Minute {
+			t.Errorf("unexpected jitter key %q or max %v", j.Key, j.Max)
+		}
+		if union.Schedules[0] != daily || nested.Key != "" {
+			t.Errorf("unexpected schedule %v or key %q", union.Schedules[0], nested.Key)
+		}
+	}
+	// So are the RandomSchedules, as when parsed by AddJob.
+	random := Random(2*time.Hour, 4*time.Hour, Daily, "")
+	cron.Schedule(random, Job{Name: "Fifth Job", Func: func(context.Context) error { return nil }})
+	cron.Schedule(Random(2*time.Hour, 4*time.Hour, Daily, "backup"), Job{Name: "Sixth Job", Func: func(context.Context) error { return nil }})
+	for _, entry := range cron.Entries() {
+		switch entry.Job.Name {
+		case "Fifth Job":
+			if r := entry.Schedule.(*RandomSchedule); r.Key != "fifth_job" || random.Key != "" {
+				t.Errorf("unexpected random key %q or %q", r.Key, random.Key)
+			}
+		case "Sixth Job":
+			if r := entry.Schedule.(*RandomSchedule); r.Key != "backup" {
+				t.Errorf("unexpected random key %q", r.Key)
+			}
+		}
+	}
+}
+
+// Test that the jobs which are never activated are rejected or reported.
+func TestUnsatisfiableJob(t *testing.T) {
+	errs := make(chan error, 1)
+	cron, err := New(WithErrorsHandler(func(_ context.Context, _ Job, err error) {
+		errs <- err
+	}))
+	if err != nil {
+		t.Fatal("unexpected error")
+	}
+
+	err = cron.AddJob(Job{Name: "test-unsatisfiable", Rhythm: "0 0 0 30 Feb *"})
+	if !errors.Is(err, ErrUnsatisfiable) {
+		t.Errorf("(expected) ErrUnsatisfiable != %v (actual)", err)
+	}
+
+	// Schedules which are not activated are reported when the cron starts.
+	cron.Schedule(Once(time.Now().Add(-time.Hour)), Job{Name: "test-unsatisfiable"})
+	cron.Start(context.Background())
+	defer cron.Stop()
+
+	select {
+	case <-time.After(ONE_SECOND):
+		t.Fatal("expected an error")
+	case err := <-errs:
+		if !errors.Is(err, ErrUnsatisfiable) {
+			t.Errorf("(expected) ErrUnsatisfiable != %v (actual)", err)
+		}
+	}
+}
+
 // Simple test using Runnables.
 func TestJob(t *testing.T) {
 	wg := &sync.WaitGroup{}
@@ -339,13 +627,17 @@ func TestJob(t *testing.T) {
 	case <-wait(wg):
 	}
 
-	// Ensure the entries are in the right order.
-	expecteds := []string{"job2", "job4", "job5", "job1", "job3", "job0"}
+	// Ensure the entries are in the right order, and that job0, which never
+	// runs, was rejected by AddJob.
+	expecteds := []string{"job2", "job4", "job5", "job1", "job3"}
 
 	var actuals []string
 	for _, entry := range cron.Entries() {
 		actuals = append(actuals, entry.Job.Name)
 	}
+	if len(actuals) != len(expecteds) {
+		t.Fatalf("Unexpected jobs.  (expected) %s != %s (actual)", expecteds, actuals)
+	}
 
 	for i, expected := range expecteds {
 		if actuals[i] != expected {
@@ -355,6 +647,57 @@ func TestJob(t *testing.T) {
 	}
 }
 
+// Test that the locks of the sub-second runs are named after their milliseconds.
+func TestLockName(t *testing.T) {
+	job := Job{Name: "Poll Job"}
+	tests := []struct {
+		time     time.Time
+		expected string
+	}{
+		{time.Unix(1781000000, 0), "etcd_cron/poll_job/1781000000"},
+		{time.Unix(1781000000, 999999), "etcd_cron/poll_job/1781000000"},
+		{time.Unix(1781000000, int64(250*time.Millisecond)), "etcd_cron/poll_job/1781000000.250"},
+		{time.Unix(1781000000, int64(5*time.Millisecond)), "etcd_cron/poll_job/1781000000.005"},
+	}
+
+	for _, c := range tests {
+		if actual := lockName(job, c.time); actual != c.expected {
+			t.Errorf("%v: (expected) %q != %q (actual)", c.time, c.expected, actual)
+		}
+	}
+}
+
+// Test that a sub-second job shared by two crons runs once per activation.
+func TestSubSecondJob(t *testing.T) {
+	var runs int32
+	job := Job{
+		Name:   "test-sub-second",
+		Rhythm: "@every 250ms",
+		Func: func(context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		},
+	}
+
+	// Both crons share the locks of the runs, which are run once.
+	for i := 0; i < 2; i++ {
+		cron, err := New()
+		if err != nil {
+			t.Fatal("unexpected error")
+		}
+		defer cron.Stop()
+		if err := cron.AddJob(job); err != nil {
+			t.Fatal(err)
+		}
+		cron.Start(context.Background())
+	}
+
+	<-time.After(time.Second + 100*time.Millisecond)
+	if actual := atomic.LoadInt32(&runs); actual < 3 || actual > 5 {
+		t.Errorf("(expected) 4 runs != %d (actual)", actual)
+	}
+}
+
 // TestCron_Parallel tests that with 2 crons with the same job
 // They should only execute once each job event
 func TestCron_Parallel(t *testing.T) {
@@ -394,6 +737,25 @@ func TestCron_Parallel(t *testing.T) {
 	}
 }
 
+// nonLocalLocation returns a time zone whose offset differs from the local one.
+// None of the candidates is at UTC offset either.
+func nonLocalLocation(t *testing.T) *time.Location {
+	for _, name := range []string{"Atlantic/Cape_Verde", "Asia/Kolkata"} {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		now := time.Now()
+		_, locOffset := now.In(loc).Zone()
+		_, localOffset := now.Zone()
+		if locOffset != localOffset {
+			return loc
+		}
+	}
+	t.Fatal("no suitable non local time zone")
+	return nil
+}
+
 func wait(wg *sync.WaitGroup) chan bool {
 	ch := make(chan bool)
 	go func() {
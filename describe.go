@@ -0,0 +1,484 @@
+package etcdcron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownLocale is returned by Describe for unsupported locales.
+var ErrUnknownLocale = errors.New("unknown locale")
+
+// locale holds the words and phrases of a language used by Describe. The
+// phrases are fmt formats.
+type locale struct {
+	and, or, through string
+	weekdays, months []string
+	ordinals         []string
+
+	// Units, by Frequency.
+	every, everyN []string
+	everyNFrom    []string
+	atOne, atMany []string
+
+	// Amounts of time, by Frequency up to weeks.
+	amountOne, amountN []string
+	offsetBy           string
+
+	// Milliseconds, in the fixed periods and the amounts of time.
+	everyMillisecond, everyNMilliseconds string
+	oneMillisecond, nMilliseconds        string
+
+	at, times               string
+	hoursBetween, hoursList string
+	everyNHoursFrom         string
+	dom, doms               string
+	lastDay, beforeLastDay  string
+	nearestWeekday          string
+	lastWeekday             string
+	dow, dowRange, dows     string
+	workWeek, weekend       string
+	dowLast, dowNth         string
+	in, everyNMonths        string
+	monthRange              string
+	location                string
+	once                    string
+	startingAt              string
+	timestamp               string
+}
+
+// The supported locales. The units are indexed by Frequency: seconds,
+// minutes, hours, days, weeks, months.
+var locales = map[string]*locale{
+	"en": {
+		and:      "and",
+		or:       "or",
+		through:  "%s through %s",
+		weekdays: []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		months: []string{"", "January", "February", "March", "April", "May", "June", "July", "August",
+			"September", "October", "November", "December"},
+		ordinals: []string{"first", "second", "third", "fourth", "fifth"},
+
+		every:      []string{"every second", "every minute", "every hour", "every day", "every week", "every month"},
+		everyN:     []string{"every %d seconds", "every %d minutes", "every %d hours", "every %d days", "every %d weeks", "every %d months"},
+		everyNFrom: []string{"every %d seconds from second %d", "every %d minutes from minute %d"},
+		atOne:      []string{"at second %s", "at minute %s"},
+		atMany:     []string{"at seconds %s", "at minutes %s"},
+
+		amountOne: []string{"1 second", "1 minute", "1 hour", "1 day", "1 week"},
+		amountN:   []string{"%d seconds", "%d minutes", "%d hours", "%d days", "%d weeks"},
+		offsetBy:  "%s, offset by %s",
+
+		everyMillisecond:   "every millisecond",
+		everyNMilliseconds: "every %d milliseconds",
+		oneMillisecond:     "1 millisecond",
+		nMilliseconds:      "%d milliseconds",
+
+		at:              "at %s",
+		times:           "%d times",
+		hoursBetween:    "between %02d:00 and %02d:59",
+		hoursList:       "during hours %s",
+		everyNHoursFrom: "every %d hours from %02d:00",
+		dom:             "on day %s of the month",
+		doms:            "on days %s of the month",
+		lastDay:         "on the last day of the month",
+		beforeLastDay:   "%d days before the last day of the month",
+		nearestWeekday:  "on the weekday nearest day %d of the month",
+		lastWeekday:     "on the last weekday of the month",
+		dow:             "%s",
+		dowRange:        "%s through %s",
+		dows:            "on %s",
+		workWeek:        "on every weekday",
+		weekend:         "on weekends",
+		dowLast:         "on the last %s of the month",
+		dowNth:          "on the %s %s of the month",
+		in:              "in %s",
+		everyNMonths:    "every %d months",
+		monthRange:      "%s through %s",
+		location:        "(%s time)",
+		once:            "once, at %s",
+		startingAt:      "starting at %s",
+		timestamp:       "2006-01-02 15:04:05 MST",
+	},
+	"fr": {
+		and:      "et",
+		or:       "ou",
+		through:  "%s à %s",
+		weekdays: []string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		months: []string{"", "janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août",
+			"septembre", "octobre", "novembre", "décembre"},
+		ordinals: []string{"premier", "deuxième", "troisième", "quatrième", "cinquième"},
+
+		every:      []string{"toutes les secondes", "toutes les minutes", "toutes les heures", "tous les jours", "toutes les semaines", "tous les mois"},
+		everyN:     []string{"toutes les %d secondes", "toutes les %d minutes", "toutes les %d heures", "tous les %d jours", "toutes les %d semaines", "tous les %d mois"},
+		everyNFrom: []string{"toutes les %d secondes à partir de la seconde %d", "toutes les %d minutes à partir de la minute %d"},
+		atOne:      []string{"à la seconde %s", "à la minute %s"},
+		atMany:     []string{"aux secondes %s", "aux minutes %s"},
+
+		amountOne: []string{"1 seconde", "1 minute", "1 heure", "1 jour", "1 semaine"},
+		amountN:   []string{"%d secondes", "%d minutes", "%d heures", "%d jours", "%d semaines"},
+		offsetBy:  "%s, avec un décalage de %s",
+
+		everyMillisecond:   "toutes les millisecondes",
+		everyNMilliseconds: "toutes les %d millisecondes",
+		oneMillisecond:     "1 milliseconde",
+		nMilliseconds:      "%d millisecondes",
+
+		at:              "à %s",
+		times:           "%d fois",
+		hoursBetween:    "entre %02d:00 et %02d:59",
+		hoursList:       "pendant les heures %s",
+		everyNHoursFrom: "toutes les %d heures à partir de %02d:00",
+		dom:             "le %s du mois",
+		doms:            "les jours %s du mois",
+		lastDay:         "le dernier jour du mois",
+		beforeLastDay:   "%d jours avant le dernier jour du mois",
+		nearestWeekday:  "le jour ouvré le plus proche du %d du mois",
+		lastWeekday:     "le dernier jour ouvré du mois",
+		dow:             "le %s",
+		dowRange:        "du %s au %s",
+		dows:            "%s",
+		workWeek:        "en semaine",
+		weekend:         "le week-end",
+		dowLast:         "le dernier %s du mois",
+		dowNth:          "le %s %s du mois",
+		in:              "en %s",
+		everyNMonths:    "tous les %d mois",
+		monthRange:      "de %s à %s",
+		location:        "(heure %s)",
+		once:            "une fois, le %s",
+		startingAt:      "à partir du %s",
+		timestamp:       "02/01/2006 15:04:05 MST",
+	},
+}
+
+// Describe returns a human-readable description of the given schedule in the
+// given locale, e.g. "At 02:30 on every weekday in March". The supported
+// locales are English ("en") and French ("fr"), possibly with a region, e.g.
+// "fr-FR".
+//
+// The description is computed from the schedule itself rather than from the
+// spec it was parsed from, so that it always matches the activation times of
+// the schedule. It supports the SpecSchedule, CalendarEventSchedule,
+// ConstantDelaySchedule, OnceSchedule and IntervalSchedule, the other
+// schedules are described by their String method if they have one.
+func Describe(schedule Schedule, localeName string) (string, error) {
+	language := strings.ToLower(localeName)
+	if i := strings.IndexAny(language, "-_"); i != -1 {
+		language = language[:i]
+	}
+	l, ok := locales[language]
+	if !ok {
+		return "", errors.Wrapf(ErrUnknownLocale, "%q", localeName)
+	}
+
+	var description string
+	switch s := schedule.(type) {
+	case *SpecSchedule:
+		description = l.describeSpec(s)
+	case *CalendarEventSchedule:
+		description = l.describeSpec(&s.SpecSchedule)
+	case ConstantDelaySchedule:
+		description = l.describeDuration(s.Delay)
+		if offset := s.offset(); offset != 0 {
+			description = fmt.Sprintf(l.offsetBy, description, l.describeAmount(offset))
+		}
+	case OnceSchedule:
+		description = fmt.Sprintf(l.once, s.At.Format(l.timestamp))
+	case *IntervalSchedule:
+		description = l.describeDuration(s.Interval) + ", " + fmt.Sprintf(l.startingAt, s.Start.Format(l.timestamp))
+		if s.Count > 0 {
+			description = fmt.Sprintf(l.times, s.Count) + ", " + description
+		}
+	case fmt.Stringer:
+		return s.String(), nil
+	default:
+		return "", errors.Errorf("unsupported schedule type %T", schedule)
+	}
+	return capitalize(description), nil
+}
+
+// describeSpec returns the description of a SpecSchedule.
+func (l *locale) describeSpec(s *SpecSchedule) string {
+	phrases := []string{l.describeTime(s)}
+	if days := l.describeDays(s); days != "" {
+		phrases = append(phrases, days)
+	}
+	if s.Month&^starBit != getBits(months.min, months.max, 1) {
+		if step, ok := bitsStep(s.Month, months); ok && s.Month&starBit > 0 {
+			phrases = append(phrases, fmt.Sprintf(l.everyNMonths, step))
+		} else {
+			phrases = append(phrases, fmt.Sprintf(l.in, l.list(l.items(s.Month, months, l.monthRange, func(v uint) string { return l.months[v] }), l.and)))
+		}
+	}
+	if s.Years != nil {
+		phrases[len(phrases)-1] += ","
+		phrases = append(phrases, fmt.Sprintf(l.in, l.list(l.ranges(formatYears(s.Years, "\x00"), l.through), l.and)))
+	}
+	if s.Location != nil {
+		phrases = append(phrases, fmt.Sprintf(l.location, s.Location))
+	}
+	return strings.Join(phrases, " ")
+}
+
+// describeTime returns the description of the time of day of a SpecSchedule.
+func (l *locale) describeTime(s *SpecSchedule) string {
+	secs, mins, hrs := bitsValues(s.Second, seconds), bitsValues(s.Minute, minutes), bitsValues(s.Hour, hours)
+
+	// A few times of day are listed.
+	if len(secs) == 1 && len(mins) == 1 && len(hrs) <= 6 {
+		var times []string
+		for _, h := range hrs {
+			t := fmt.Sprintf("%02d:%02d", h, mins[0])
+			if secs[0] != 0 {
+				t += fmt.Sprintf(":%02d", secs[0])
+			}
+			times = append(times, t)
+		}
+		return fmt.Sprintf(l.at, l.list(times, l.and))
+	}
+
+	var phrases []string
+	second := l.describeUnit(s.Second, seconds, Secondly)
+	if len(secs) == 1 && secs[0] == 0 {
+		second = ""
+	}
+	if second != "" {
+		phrases = append(phrases, second)
+	}
+	if len(mins) < 60 || second == "" {
+		phrases = append(phrases, l.describeUnit(s.Minute, minutes, Minutely))
+	}
+	if len(hrs) < 24 {
+		phrases = append(phrases, l.describeHours(hrs))
+	}
+	return strings.Join(phrases, ", ")
+}
+
+// describeUnit returns the description of the seconds or minutes of a
+// SpecSchedule.
+func (l *locale) describeUnit(bits uint64, r bounds, unit Frequency) string {
+	values := bitsValues(bits, r)
+	if len(values) == int(r.max-r.min+1) {
+		return l.every[unit]
+	}
+	if step, ok := bitsStep(bits, r); ok {
+		if values[0] == r.min {
+			return fmt.Sprintf(l.everyN[unit], step)
+		}
+		return fmt.Sprintf(l.everyNFrom[unit], step, values[0])
+	}
+	items := l.items(bits, r, l.through, func(v uint) string { return strconv.Itoa(int(v)) })
+	if len(values) == 1 {
+		return fmt.Sprintf(l.atOne[unit], items[0])
+	}
+	return fmt.Sprintf(l.atMany[unit], l.list(items, l.and))
+}
+
+// describeHours returns the description of the hours of a SpecSchedule.
+func (l *locale) describeHours(hrs []uint) string {
+	var bits uint64
+	for _, h := range hrs {
+		bits |= 1 << h
+	}
+	if step, ok := bitsStep(bits, hours); ok {
+		if hrs[0] == 0 {
+			return fmt.Sprintf(l.everyN[Hourly], step)
+		}
+		return fmt.Sprintf(l.everyNHoursFrom, step, hrs[0])
+	}
+	if hrs[len(hrs)-1]-hrs[0] == uint(len(hrs)-1) {
+		return fmt.Sprintf(l.hoursBetween, hrs[0], hrs[len(hrs)-1])
+	}
+	return fmt.Sprintf(l.hoursList, l.list(l.items(bits, hours, l.through, func(v uint) string { return strconv.Itoa(int(v)) }), l.and))
+}
+
+// describeDays returns the description of the days of month and of week of a
+// SpecSchedule, empty if it runs every day.
+func (l *locale) describeDays(s *SpecSchedule) string {
+	var domPhrases, dowPhrases []string
+
+	if domBits := s.Dom &^ starBit; domBits != getBits(dom.min, dom.max, 1) {
+		if step, ok := bitsStep(s.Dom, dom); ok && s.Dom&starBit > 0 {
+			domPhrases = append(domPhrases, fmt.Sprintf(l.everyN[Daily], step))
+		} else if values := bitsValues(domBits, dom); len(values) == 1 {
+			domPhrases = append(domPhrases, fmt.Sprintf(l.dom, strconv.Itoa(int(values[0]))))
+		} else if len(values) > 1 {
+			domPhrases = append(domPhrases, fmt.Sprintf(l.doms, l.list(l.items(domBits, dom, l.through, func(v uint) string { return strconv.Itoa(int(v)) }), l.and)))
+		}
+	}
+	for n := uint(0); n < 31; n++ {
+		if 1<<n&s.DomLast > 0 {
+			if n == 0 {
+				domPhrases = append(domPhrases, l.lastDay)
+			} else {
+				domPhrases = append(domPhrases, fmt.Sprintf(l.beforeLastDay, n))
+			}
+		}
+	}
+	for n := uint(0); n <= 31; n++ {
+		if 1<<n&s.DomWeekday > 0 {
+			if n == 0 {
+				domPhrases = append(domPhrases, l.lastWeekday)
+			} else {
+				domPhrases = append(domPhrases, fmt.Sprintf(l.nearestWeekday, n))
+			}
+		}
+	}
+
+	switch dowBits := s.Dow &^ starBit; dowBits {
+	case getBits(dow.min, dow.max, 1):
+	case getBits(1, 5, 1):
+		dowPhrases = append(dowPhrases, l.workWeek)
+	case 1<<0 | 1<<6:
+		dowPhrases = append(dowPhrases, l.weekend)
+	case 0:
+	default:
+		var days []string
+		for d := uint(0); d < 7; d++ {
+			if dowBits&(1<<d) == 0 {
+				continue
+			}
+			end := d
+			for end < 6 && dowBits&(1<<(end+1)) > 0 {
+				end++
+			}
+			if end-d >= 2 {
+				days = append(days, fmt.Sprintf(l.dowRange, l.weekdays[d], l.weekdays[end]))
+				d = end
+			} else {
+				days = append(days, fmt.Sprintf(l.dow, l.weekdays[d]))
+			}
+		}
+		dowPhrases = append(dowPhrases, fmt.Sprintf(l.dows, l.list(days, l.and)))
+	}
+	for d := uint(0); d < 7; d++ {
+		if 1<<d&s.DowLast > 0 {
+			dowPhrases = append(dowPhrases, fmt.Sprintf(l.dowLast, l.weekdays[d]))
+		}
+	}
+	for i := uint(0); i < 35; i++ {
+		if 1<<i&s.DowNth > 0 {
+			dowPhrases = append(dowPhrases, fmt.Sprintf(l.dowNth, l.ordinals[i/7], l.weekdays[i%7]))
+		}
+	}
+
+	switch {
+	case len(domPhrases) == 0:
+		return l.list(dowPhrases, l.and)
+	case len(dowPhrases) == 0:
+		return l.list(domPhrases, l.and)
+	case s.Dom&starBit > 0 || s.Dow&starBit > 0:
+		return l.list(domPhrases, l.and) + " " + l.and + " " + l.list(dowPhrases, l.and)
+	default:
+		return l.list(domPhrases, l.and) + " " + l.or + " " + l.list(dowPhrases, l.and)
+	}
+}
+
+// durationUnits are the units of the fixed periods, from the largest.
+var durationUnits = []struct {
+	frequency Frequency
+	duration  time.Duration
+}{
+	{Weekly, 7 * 24 * time.Hour},
+	{Daily, 24 * time.Hour},
+	{Hourly, time.Hour},
+	{Minutely, time.Minute},
+	{Secondly, time.Second},
+}
+
+// describeDuration returns the description of a fixed period, in the largest
+// unit which divides it down to the millisecond, e.g. "every 90 minutes".
+func (l *locale) describeDuration(d time.Duration) string {
+	for _, unit := range durationUnits {
+		if d%unit.duration == 0 {
+			n := int(d / unit.duration)
+			if n == 1 {
+				return l.every[unit.frequency]
+			}
+			return fmt.Sprintf(l.everyN[unit.frequency], n)
+		}
+	}
+	if n := int(d / time.Millisecond); n != 1 {
+		return fmt.Sprintf(l.everyNMilliseconds, n)
+	}
+	return l.everyMillisecond
+}
+
+// describeAmount returns the description of an amount of time, in the largest
+// unit which divides it down to the millisecond, e.g. "15 minutes".
+func (l *locale) describeAmount(d time.Duration) string {
+	for _, unit := range durationUnits {
+		if d%unit.duration == 0 {
+			n := int(d / unit.duration)
+			if n == 1 {
+				return l.amountOne[unit.frequency]
+			}
+			return fmt.Sprintf(l.amountN[unit.frequency], n)
+		}
+	}
+	if n := int(d / time.Millisecond); n != 1 {
+		return fmt.Sprintf(l.nMilliseconds, n)
+	}
+	return l.oneMillisecond
+}
+
+// items returns the values of the given bits, with the runs of more than 2
+// consecutive values as ranges.
+func (l *locale) items(bits uint64, r bounds, through string, format func(uint) string) []string {
+	return l.ranges(formatRanges(bits&^starBit, r.min, r.max, "\x00", format), through)
+}
+
+// ranges formats the items separated by a NUL character as ranges.
+func (l *locale) ranges(items []string, through string) []string {
+	for i, item := range items {
+		if bounds := strings.Split(item, "\x00"); len(bounds) == 2 {
+			items[i] = fmt.Sprintf(through, bounds[0], bounds[1])
+		}
+	}
+	return items
+}
+
+// list joins the given items as a list, e.g. "a, b and c".
+func (l *locale) list(items []string, conjunction string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " " + conjunction + " " + items[len(items)-1]
+}
+
+// bitsValues returns the values of the given bits within the bounds.
+func bitsValues(bits uint64, r bounds) []uint {
+	var values []uint
+	for v := r.min; v <= r.max; v++ {
+		if bits&(1<<v) > 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// bitsStep returns the step of the given bits if they are at least 3 values
+// repeated up to the end of the bounds, e.g. "5-59/15".
+func bitsStep(bits uint64, r bounds) (uint, bool) {
+	values := bitsValues(bits, r)
+	if len(values) < 3 {
+		return 0, false
+	}
+	step := values[1] - values[0]
+	if step < 2 || getBits(values[0], r.max, step) != bits&^starBit {
+		return 0, false
+	}
+	return step, true
+}
+
+// capitalize returns the given text with its first letter in upper case.
+func capitalize(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(r)) + text[size:]
+}
@@ -0,0 +1,90 @@
+package etcdcron
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		spec string
+		en   string
+		fr   string
+	}{
+		{"0 30 2 * 3 1-5", "At 02:30 on every weekday in March", "À 02:30 en semaine en mars"},
+		{"0 */15 9-17 * * *", "Every 15 minutes, between 09:00 and 17:59", "Toutes les 15 minutes, entre 09:00 et 17:59"},
+		{"* * * * * *", "Every second", "Toutes les secondes"},
+		{"*/10 * * * * *", "Every 10 seconds", "Toutes les 10 secondes"},
+		{"0 5-59/20 * * * *", "Every 20 minutes from minute 5", "Toutes les 20 minutes à partir de la minute 5"},
+		{"0 1,2,3,4,20-40 * * * *", "At minutes 1 through 4 and 20 through 40", "Aux minutes 1 à 4 et 20 à 40"},
+		{"0 5 */2 * * *", "At minute 5, every 2 hours", "À la minute 5, toutes les 2 heures"},
+		{"0 0 9,12,15 * * *", "At 09:00, 12:00 and 15:00", "À 09:00, 12:00 et 15:00"},
+		{"15 30 8 * * *", "At 08:30:15", "À 08:30:15"},
+		{"0 0 0 1,15 * *", "At 00:00 on days 1 and 15 of the month", "À 00:00 les jours 1 et 15 du mois"},
+		{"0 0 0 */2 * *", "At 00:00 every 2 days", "À 00:00 tous les 2 jours"},
+		{"0 0 0 L * *", "At 00:00 on the last day of the month", "À 00:00 le dernier jour du mois"},
+		{"0 0 0 15W * *", "At 00:00 on the weekday nearest day 15 of the month", "À 00:00 le jour ouvré le plus proche du 15 du mois"},
+		{"0 0 0 LW * *", "At 00:00 on the last weekday of the month", "À 00:00 le dernier jour ouvré du mois"},
+		{"0 0 12 * * 5L", "At 12:00 on the last Friday of the month", "À 12:00 le dernier vendredi du mois"},
+		{"0 0 12 * * 2#3", "At 12:00 on the third Tuesday of the month", "À 12:00 le troisième mardi du mois"},
+		{"0 0 0 * * 1,3,5", "At 00:00 on Monday, Wednesday and Friday", "À 00:00 le lundi, le mercredi et le vendredi"},
+		{"0 0 0 13 * 5", "At 00:00 on day 13 of the month or on Friday", "À 00:00 le 13 du mois ou le vendredi"},
+		{"0 0 0 1 */3 *", "At 00:00 on day 1 of the month every 3 months", "À 00:00 le 1 du mois tous les 3 mois"},
+		{"0 0 0 1 1 * 2027-2030", "At 00:00 on day 1 of the month in January, in 2027 through 2030", "À 00:00 le 1 du mois en janvier, en 2027 à 2030"},
+		{"CRON_TZ=Europe/Paris 0 0 6 * * 0,6", "At 06:00 on weekends (Europe/Paris time)", "À 06:00 le week-end (heure Europe/Paris)"},
+		{"@daily", "At 00:00", "À 00:00"},
+		{"OnCalendar=Fri *-*-13 00:00", "At 00:00 on day 13 of the month and on Friday", "À 00:00 le 13 du mois et le vendredi"},
+		{"@every 1h30m", "Every 90 minutes", "Toutes les 90 minutes"},
+		{"@every 24h", "Every day", "Tous les jours"},
+		{"@every 1h offset 15m", "Every hour, offset by 15 minutes", "Toutes les heures, avec un décalage de 15 minutes"},
+		{"@every 24h from 2026-01-05T09:00:00+01:00", "Every day, offset by 8 hours", "Tous les jours, avec un décalage de 8 heures"},
+		{"@every 1h offset 60m", "Every hour", "Toutes les heures"},
+		{"@every 250ms", "Every 250 milliseconds", "Toutes les 250 millisecondes"},
+		{"@every 1500ms", "Every 1500 milliseconds", "Toutes les 1500 millisecondes"},
+		{"@every 1ms", "Every millisecond", "Toutes les millisecondes"},
+		{"@every 1s offset 250ms", "Every second, offset by 250 milliseconds", "Toutes les secondes, avec un décalage de 250 millisecondes"},
+		{"@at 2026-11-01T03:00:00Z", "Once, at 2026-11-01 03:00:00 UTC", "Une fois, le 01/11/2026 03:00:00 UTC"},
+		{"R5/2026-11-01T03:00:00Z/PT2H", "5 times, every 2 hours, starting at 2026-11-01 03:00:00 UTC", "5 fois, toutes les 2 heures, à partir du 01/11/2026 03:00:00 UTC"},
+	}
+
+	for _, c := range tests {
+		schedule, err := Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		for _, l := range []struct{ locale, expected string }{{"en", c.en}, {"fr-FR", c.fr}} {
+			actual, err := Describe(schedule, l.locale)
+			if err != nil {
+				t.Errorf("%s, %s: %v", c.spec, l.locale, err)
+				continue
+			}
+			if actual != l.expected {
+				t.Errorf("%s, %s: expected %q, got %q", c.spec, l.locale, l.expected, actual)
+			}
+		}
+	}
+}
+
+func TestDescribeErrors(t *testing.T) {
+	schedule, err := Parse("@daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Describe(schedule, "de"); errors.Cause(err) != ErrUnknownLocale {
+		t.Errorf("expected ErrUnknownLocale, got %v", err)
+	}
+
+	rrule, err := Parse("RRULE:FREQ=DAILY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := Describe(rrule, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := rrule.(*RRuleSchedule).String(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
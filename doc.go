@@ -22,22 +22,43 @@ them in their own goroutines.
 	..
 	c.Stop()  // Stop the scheduler (does not stop any jobs already running).
 
+Clusters
+
+Each run of a job is guarded by an etcd lock named after the job and its
+activation time, so that a single node of the cluster runs it. The activation
+times must then be the same on every node: the ones derived from the name of
+the job, such as "H", "@random" and Job.Jitter, or aligned on a fixed time, such
+as "@every", only depend on the spec and on the name, never on the node nor on
+the time the Cron was started.
+
 CRON Expression Format
 
-A cron expression represents a set of times, using 6 space-separated fields.
+A cron expression represents a set of times, using 5 to 7 space-separated
+fields.
 
 	Field name   | Mandatory? | Allowed values  | Allowed special characters
 	----------   | ---------- | --------------  | --------------------------
-	Seconds      | Yes        | 0-59            | * / , -
-	Minutes      | Yes        | 0-59            | * / , -
-	Hours        | Yes        | 0-23            | * / , -
-	Day of month | Yes        | 1-31            | * / , - ?
-	Month        | Yes        | 1-12 or JAN-DEC | * / , -
-	Day of week  | Yes        | 0-6 or SUN-SAT  | * / , - ?
+	Seconds      | Yes        | 0-59            | * / , - H
+	Minutes      | Yes        | 0-59            | * / , - H
+	Hours        | Yes        | 0-23            | * / , - H
+	Day of month | Yes        | 1-31            | * / , - ? L W H
+	Month        | Yes        | 1-12 or JAN-DEC | * / , - H
+	Day of week  | No         | 0-6 or SUN-SAT  | * / , - ? L # H
+	Year         | No         | 1970-2099       | * / , -
 
 Note: Month and Day-of-week field values are case insensitive.  "SUN", "Sun",
 and "sun" are equally accepted.
 
+The layout of the fields can be changed with a Parser, given to the Cron with
+the WithParser option. For example, NewParser(WithStandardLayout()) parses the
+5 fields of the standard Unix crontab, starting with the minutes.
+
+A missing Day-of-week or Year field is equivalent to a star. When looking for
+the next activation time, the schedule looks 5 years ahead (DefaultHorizon)
+before giving up, unless it is restricted to some years: it is then searched up
+to the last of them. The horizon can be changed with SpecSchedule.Horizon or the WithHorizon parser
+option.
+
 Special Characters
 
 Asterisk ( * )
@@ -65,11 +86,45 @@ Hyphen ( - )
 Hyphens are used to define ranges. For example, 9-17 would indicate every
 hour between 9am and 5pm inclusive.
 
+A range whose beginning is beyond its end wraps around, except in the year
+field. For example, 22-2 in the hours field would indicate every hour between
+10pm and 2am inclusive, FRI-MON in the day-of-week field Friday to Monday, and
+22-4/2 the hours 22, 0, 2 and 4.
+
 Question mark ( ? )
 
 Question mark may be used instead of '*' for leaving either day-of-month or
 day-of-week blank.
 
+L ( L )
+
+In the day-of-month field, "L" stands for the last day of the month, and "L-3"
+for the third day before it. In the day-of-week field, "5L" or "FRIL" stands
+for the last Friday of the month.
+
+W ( W )
+
+In the day-of-month field, "15W" stands for the weekday (Monday to Friday)
+nearest to the 15th of the month, without leaving the month: if the 15th is a
+Saturday the job runs on Friday the 14th, if it is a Sunday on Monday the 16th.
+"LW" stands for the last weekday of the month.
+
+Hash ( # )
+
+In the day-of-week field, "2#3" or "TUE#3" stands for the third Tuesday of the
+month.
+
+These modifiers can be mixed with other values in a list, e.g. "1,15,L".
+
+Hash ( H )
+
+"H" stands for a value of the field derived from a hash of the job name, so
+that jobs sharing the same spec don't all run at the same time. "H(0-29)" picks
+such a value within a range, and "H/15" runs every 15 units starting from such an offset.
+For example, "H H 3 * * *" runs each job once between 3am and 4am. In the
+day-of-month field, "H" only picks days between 1 and 28. Specs parsed outside
+of a Cron use the key given to ParseHashed.
+
 Predefined schedules
 
 You may use one of several pre-defined schedules in place of a cron expression.
@@ -95,17 +150,206 @@ where "duration" is a string accepted by time.ParseDuration
 For example, "@every 1h30m10s" would indicate a schedule that activates every
 1 hour, 30 minutes, 10 seconds.
 
+The activation times are aligned on the Unix epoch: "@every 1h" is activated on
+the hour, whatever the time the Cron was started. They can be
+moved by an offset, or aligned on a given RFC 3339 time:
+
+    @every 1h offset 15m
+    @every 24h from 2026-01-05T09:00:00+01:00
+
+Every returns a schedule activated a duration after the time the Cron was
+started instead, which isn't shared across the cluster; EveryFrom returns an
+aligned one.
+
+Intervals have a millisecond resolution, e.g. "@every 250ms", while the other
+schedules have a second resolution. The lock of a run whose activation time
+isn't on the second is named after its Unix time in milliseconds, e.g.
+"etcd_cron/<job>/1781000000.250", so that the runs within the same second don't
+share their lock.
+
 Note: The interval does not take the job runtime into account.  For example,
 if a job takes 3 minutes to run, and it is scheduled to run every 5 minutes,
 it will have only 2 minutes of idle time between each run.
 
+Random windows
+
+A job can be run once per day, or once per week, at a pseudo-random time
+within a window, so that the jobs of several tenants don't start together:
+
+    @random 02:00-04:00 daily
+    @random 22:00-02:00 weekly
+
+The time of each day or week is derived from the name of the job and from the
+period. The window ends on the next day if its end is not after its
+start, and the weekly day is pseudo-random as well, the weeks starting on
+Monday. Random returns such a schedule for any key, the name of the job if
+empty when given to Cron.Schedule. A key given in the
+descriptor replaces the name of the job, as in the String of the keyed
+schedules:
+
+    @random 02:00-04:00 daily key "backup"
+
+Sunrise and sunset
+
+A job can be run every day at the sunrise or the sunset at a given latitude and
+longitude, in decimal degrees, optionally moved by an offset:
+
+    @sunrise 48.8566 2.3522
+    @sunset 48.8566 2.3522 30m
+    @sunrise -33.8688 151.2093 -1h
+
+The times are computed offline with the NOAA solar calculation algorithm, and
+truncated to the minute, as the floating-point results may differ slightly
+between architectures. The days when the sun doesn't rise or set, beyond
+the polar circles, are skipped. Solar returns such a schedule.
+
+Recurrence rules
+
+Calendar-style recurrences which can't be expressed with a cron expression can
+be given as RFC 5545 (iCalendar) recurrence rules, made of "DTSTART", "RRULE"
+and "EXDATE" content lines separated by spaces or new lines:
+
+	RRULE:FREQ=MONTHLY;INTERVAL=3;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1
+	DTSTART;TZID=Europe/Paris:20260105T090000 RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20270630T215959Z
+
+Without DTSTART, the rule starts on January 1st, 1970 at midnight, so a rule
+with a COUNT requires a DTSTART. A DTSTART
+without "TZID" nor "Z" suffix is a floating time, evaluated in the time zone of
+the job as the cron expressions are, or in the time zone of a "CRON_TZ=" prefix.
+See ParseRRule for details.
+
+systemd calendar events
+
+The OnCalendar= expressions of the systemd timer units can be used as is,
+prefixed by "OnCalendar=":
+
+	OnCalendar=Mon..Fri *-*-* 09:00:00
+	OnCalendar=*-*-01 04:00
+	OnCalendar=weekly Europe/Paris
+
+Contrary to cron expressions, both the day of week and the day of month must
+match. A time zone suffix can't differ from the one of a "CRON_TZ=" prefix. See
+ParseCalendarEvent for details.
+
+AWS EventBridge expressions
+
+The cron and rate expressions of AWS EventBridge can be used as is:
+
+	cron(0 12 ? * MON-FRI *)
+	cron(15 10 ? * 6L 2026-2027)
+	rate(5 minutes)
+
+Their cron fields are "minute hour dom month dow year", exactly one of the day
+fields being "?", and the days of the week are numbered from 1 (Sunday) to 7
+(Saturday). They are evaluated in UTC, unless prefixed by a time zone. The rate
+expressions are aligned on the Unix epoch as "@every". See ParseEventBridge for
+details.
+
+One-shot and repeating intervals
+
+A job can be run only once, at a given RFC 3339 time:
+
+	@at 2026-11-01T03:00:00Z
+
+It can also be run a given number of times, at a fixed interval, with an
+ISO 8601 repeating interval "R[n]/<start>/<duration>". Without n, the job is
+repeated forever. For example, to run 5 times every 90 minutes:
+
+	R5/2026-11-01T03:00:00Z/PT90M
+
+Once these schedules are exhausted, their entries are removed from the Cron.
+
+Combining schedules
+
+Schedules can be combined with Union, Intersect, Except and Within, and given
+to Cron.Schedule. Except and Within take recurring Windows, which open at the
+activation times of a schedule and last a fixed duration. For example, to run
+every 10 minutes except between 23:00 and 01:00 on Sundays:
+
+	everyTenMinutes, _ := etcdcron.Parse("0 0/10 * * * *")
+	sundayNight, _ := etcdcron.Parse("0 0 23 * * 0")
+	c.Schedule(etcdcron.Except(everyTenMinutes, etcdcron.Window{
+		Start:    sundayNight,
+		Duration: 2 * time.Hour,
+	}), job)
+
+The combined schedules, and the ones wrapped by OnBusinessDays, EveryPeriod or
+Jitter, have a String method showing their parts, e.g. "Except(0 0 * * * *;
+Window(0 0 23 * * 0; 2h0m0s))", but they can't be parsed: their MarshalText
+returns an error.
+
+EveryPeriod restricts a schedule to every n-th day, week (starting on Monday),
+month or year, counted from the one containing an anchor time. For example,
+every other Monday at 09:00, and every 3 months on the 1st from March 2026:
+
+	monday, _ := etcdcron.Parse("0 0 9 * * MON")
+	c.Schedule(etcdcron.EveryPeriod(monday, etcdcron.Weekly, 2, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)), job)
+	first, _ := etcdcron.Parse("0 0 0 1 * *")
+	c.Schedule(etcdcron.EveryPeriod(first, etcdcron.Monthly, 3, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)), job)
+
+Business days
+
+A Calendar holds the weekend days and the holidays, which can be read from an
+iCalendar file or from a list of dates. OnBusinessDays applies it to any
+schedule, the activation times which are not on a business day being skipped,
+or shifted to the next or to the previous business day:
+
+	calendar := etcdcron.NewCalendar()
+	err := calendar.ReadICS(icsFile)
+	monthly, _ := etcdcron.Parse("0 0 18 1 * *")
+	c.Schedule(etcdcron.OnBusinessDays(monthly, calendar, etcdcron.ShiftForward), job)
+
+Jitter
+
+The activation times of a job can be delayed by up to Job.Jitter, to spread the
+load of jobs starting at the same time. The delays are whole milliseconds,
+derived from the name of the job and from the activation times. Any schedule
+can also be wrapped with Jitter, keyed by the name of the job given to
+Cron.Schedule, even within combined schedules; Job.Jitter then replaces the
+maximum delay of the outermost one.
+
+Unsatisfiable schedules
+
+Parse rejects the specs which can never be activated, such as "0 0 0 30 2 *"
+(February 30th), with ErrUnsatisfiable. The other kinds of specs, such as
+recurrence rules or "@at", are rejected if they have no activation time after
+January 1st 2001, e.g. "RRULE:FREQ=MONTHLY;BYMONTH=2;BYMONTHDAY=30". With the
+WithMaxInterval option, a Parser also rejects the specs whose activation times
+can be too far apart, with ErrTooRare, whatever the time they are parsed. The
+schedules with no activation time left when the Cron starts, or when they are
+added, are reported to the errors handler and dropped.
+
 Time zones
 
-All interpretation and scheduling is done in the machine's local time zone (as
-provided by the Go time package (http://www.golang.org/pkg/time).
+By default, all interpretation and scheduling is done in the machine's local
+time zone (as provided by the Go time package (http://www.golang.org/pkg/time).
+As the lock of each iteration of a job is named after its activation time, all
+the nodes of a cluster must agree on the time zone. It can be set for the whole
+Cron with the WithLocation option, or per job with the Job.Location field.
+
+The time zone can also be given in the spec itself, by prefixing it with
+"CRON_TZ=" or "TZ=" followed by the name of a location of the IANA Time Zone
+database. It takes precedence over the other settings:
+
+	CRON_TZ=Europe/Paris 0 0 6 * * *
+	TZ=UTC @daily
+
+The schedules whose activation times are absolute, "@every", "@at", the solar
+descriptors, the rate expressions and the repeating intervals, don't accept a
+time zone prefix.
+
+By default, the activation times which don't exist because of a daylight saving
+time transition (e.g. 02:30 when the clocks go from 02:00 to 03:00) are
+skipped, and the ones which are repeated (e.g. 01:30 when the clocks go from
+02:00 back to 01:00) are activated twice. The DST policy of a SpecSchedule,
+which can be set with the WithDSTPolicy parser option, can shift the former to
+the end of the transition, and activate the latter only once:
+
+	parser, _ := etcdcron.NewParser(etcdcron.WithDSTPolicy(etcdcron.DSTShiftMissing | etcdcron.DSTFirstOccurrence))
+	cron, _ := etcdcron.New(etcdcron.WithParser(parser))
 
-Be aware that jobs scheduled during daylight-savings leap-ahead transitions will
-not be run!
+Schedules running every hour are always activated on both occurrences of the
+repeated times.
 
 Thread safety
 
@@ -121,7 +365,7 @@ Cron entries are stored in an array, sorted by their next activation time.  Cron
 sleeps until the next job is due to be run.
 
 Upon waking:
- - it runs each entry that is active on that second
+ - it runs each entry that is active at that time
  - it calculates the next run times for the jobs that were run
  - it re-sorts the array of entries by next activation time.
  - it goes to sleep until the soonest job.
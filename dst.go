@@ -0,0 +1,118 @@
+package etcdcron
+
+import "time"
+
+// DSTPolicy tells how a SpecSchedule handles the daylight saving time
+// transitions of its time zone: the wall clock times skipped when the clocks
+// are set forward, and the ones repeated when they are set back. It is a
+// combination of the following flags, the zero value skipping the missing
+// times and activating the repeated ones twice.
+//
+// Schedules which run every hour, e.g. "0 */15 * * * *", are always activated
+// on both occurrences of the repeated times, so that they keep running at
+// regular intervals: DSTFirstOccurrence and DSTSecondOccurrence only apply to
+// the schedules restricted to some hours.
+type DSTPolicy int
+
+const (
+	// DSTSkipMissing skips the activation times which don't exist because the
+	// clocks are set forward, e.g. 02:30 when the clocks go from 02:00 to
+	// 03:00. It is the default.
+	DSTSkipMissing DSTPolicy = 0
+	// DSTShiftMissing moves the activation times which don't exist to the
+	// first valid instant after them, e.g. 02:30 to 03:00 when the clocks go
+	// from 02:00 to 03:00. Several activation times moved to the same instant
+	// are activated once.
+	DSTShiftMissing DSTPolicy = 1 << 0
+	// DSTFirstOccurrence activates the times repeated because the clocks are
+	// set back only once, on their first occurrence.
+	DSTFirstOccurrence DSTPolicy = 1 << 1
+	// DSTSecondOccurrence activates the times repeated because the clocks are
+	// set back only once, on their second occurrence.
+	DSTSecondOccurrence DSTPolicy = 1 << 2
+)
+
+// instants returns the instants, in order, at which the schedule is activated
+// for the given wall clock time, expressed in UTC, in the given location: none
+// or one if the time doesn't exist, one or two if it is repeated, according to
+// the DST policy.
+func (s *SpecSchedule) instants(wall time.Time, loc *time.Location) []time.Time {
+	instants := wallInstants(wall, loc)
+	switch len(instants) {
+	case 0:
+		if s.DST&DSTShiftMissing > 0 {
+			return []time.Time{gapEnd(wall, loc)}
+		}
+	case 2:
+		if s.Hour&^starBit == getBits(hours.min, hours.max, 1) {
+			break
+		}
+		first, second := s.DST&DSTFirstOccurrence > 0, s.DST&DSTSecondOccurrence > 0
+		if first && !second {
+			return instants[:1]
+		}
+		if second && !first {
+			return instants[1:]
+		}
+	}
+	return instants
+}
+
+// wallInstants returns the instants, in order, at which the clocks of the
+// given location show the given wall clock time, expressed in UTC.
+func wallInstants(wall time.Time, loc *time.Location) []time.Time {
+	// The offsets around the time are the candidates: the time is one of the
+	// instants if the offset is the one in effect at that instant.
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+	var instants []time.Time
+	for _, probe := range []time.Time{t.Add(-12 * time.Hour), t, t.Add(12 * time.Hour)} {
+		_, offset := probe.Zone()
+		instant := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if !wallClock(instant).Add(time.Duration(instant.Nanosecond())).Equal(wall) {
+			continue
+		}
+		switch {
+		case len(instants) == 0:
+			instants = append(instants, instant)
+		case instant.Before(instants[0]):
+			instants = append([]time.Time{instant}, instants...)
+		case instant.After(instants[len(instants)-1]):
+			instants = append(instants, instant)
+		}
+	}
+	return instants
+}
+
+// gapEnd returns the first instant after the given wall clock time, expressed
+// in UTC, which doesn't exist in the given location.
+func gapEnd(wall time.Time, loc *time.Location) time.Time {
+	// With the offset before the gap, the time is after it.
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+	_, offset := t.Add(-12 * time.Hour).Zone()
+	start, _ := wall.Add(-time.Duration(offset) * time.Second).In(loc).ZoneBounds()
+	return start
+}
+
+// repeatedWalls returns the wall clock times, expressed in UTC, repeated
+// because the clocks are set back, from (included) and to (excluded), if the
+// wall clock time of the given time is one of them.
+func repeatedWalls(t time.Time) (from, to time.Time, ok bool) {
+	_, offset := t.Zone()
+	start, end := t.ZoneBounds()
+
+	// In the first occurrence, before the clocks are set back.
+	if !end.IsZero() {
+		_, next := end.Zone()
+		if diff := time.Duration(offset-next) * time.Second; diff > 0 && !t.Before(end.Add(-diff)) {
+			return wallClock(end), wallClock(end).Add(diff), true
+		}
+	}
+	// In the second occurrence, after the clocks are set back.
+	if !start.IsZero() {
+		_, previous := start.Add(-time.Nanosecond).Zone()
+		if diff := time.Duration(previous-offset) * time.Second; diff > 0 && t.Before(start.Add(diff)) {
+			return wallClock(start), wallClock(start).Add(diff), true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
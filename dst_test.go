@@ -0,0 +1,143 @@
+package etcdcron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDSTPolicy(t *testing.T) {
+	tests := []struct {
+		zone, spec string
+		policy     DSTPolicy
+		from       string
+		expected   []string
+	}{
+		// The clocks go from 02:00 to 03:00 on March 8th 2026 in New York.
+		{"America/New_York", "0 30 2 * * *", DSTSkipMissing, "2026-03-07T12:00:00-05:00",
+			[]string{"2026-03-09T02:30:00-04:00", "2026-03-10T02:30:00-04:00"}},
+		{"America/New_York", "0 30 2 * * *", DSTShiftMissing, "2026-03-07T12:00:00-05:00",
+			[]string{"2026-03-08T03:00:00-04:00", "2026-03-09T02:30:00-04:00"}},
+		{"America/New_York", "0 */20 2-3 * * *", DSTShiftMissing, "2026-03-08T00:00:00-05:00",
+			[]string{"2026-03-08T03:00:00-04:00", "2026-03-08T03:20:00-04:00", "2026-03-08T03:40:00-04:00", "2026-03-09T02:00:00-04:00"}},
+		{"America/New_York", "0 */30 * * * *", DSTSkipMissing, "2026-03-08T01:00:00-05:00",
+			[]string{"2026-03-08T01:30:00-05:00", "2026-03-08T03:00:00-04:00", "2026-03-08T03:30:00-04:00"}},
+
+		// The clocks go from 02:00 back to 01:00 on November 1st 2026 in New
+		// York.
+		{"America/New_York", "0 30 1 * * *", DSTSkipMissing, "2026-10-31T12:00:00-04:00",
+			[]string{"2026-11-01T01:30:00-04:00", "2026-11-01T01:30:00-05:00", "2026-11-02T01:30:00-05:00"}},
+		{"America/New_York", "0 30 1 * * *", DSTFirstOccurrence, "2026-10-31T12:00:00-04:00",
+			[]string{"2026-11-01T01:30:00-04:00", "2026-11-02T01:30:00-05:00"}},
+		{"America/New_York", "0 30 1 * * *", DSTSecondOccurrence, "2026-10-31T12:00:00-04:00",
+			[]string{"2026-11-01T01:30:00-05:00", "2026-11-02T01:30:00-05:00"}},
+		{"America/New_York", "0 30 1 * * *", DSTFirstOccurrence | DSTSecondOccurrence, "2026-10-31T12:00:00-04:00",
+			[]string{"2026-11-01T01:30:00-04:00", "2026-11-01T01:30:00-05:00", "2026-11-02T01:30:00-05:00"}},
+		{"America/New_York", "0 0,30 1 * * *", DSTSecondOccurrence, "2026-11-01T01:15:00-04:00",
+			[]string{"2026-11-01T01:00:00-05:00", "2026-11-01T01:30:00-05:00", "2026-11-02T01:00:00-05:00"}},
+
+		// Every hour, the repeated times are always activated twice.
+		{"America/New_York", "0 */30 * * * *", DSTFirstOccurrence, "2026-11-01T00:10:00-04:00",
+			[]string{"2026-11-01T00:30:00-04:00", "2026-11-01T01:00:00-04:00", "2026-11-01T01:30:00-04:00",
+				"2026-11-01T01:00:00-05:00", "2026-11-01T01:30:00-05:00", "2026-11-01T02:00:00-05:00"}},
+
+		// The clocks go from 02:00 to 03:00 on March 29th, and from 03:00 back
+		// to 02:00 on October 25th 2026 in Paris.
+		{"Europe/Paris", "0 15 2 * * 0", DSTShiftMissing | DSTFirstOccurrence, "2026-03-28T12:00:00+01:00",
+			[]string{"2026-03-29T03:00:00+02:00", "2026-04-05T02:15:00+02:00"}},
+		{"Europe/Paris", "0 15 2 * * 0", DSTShiftMissing | DSTFirstOccurrence, "2026-10-24T12:00:00+02:00",
+			[]string{"2026-10-25T02:15:00+02:00", "2026-11-01T02:15:00+01:00"}},
+		{"Europe/Paris", "0 15 2 * * 0", DSTSecondOccurrence, "2026-10-24T12:00:00+02:00",
+			[]string{"2026-10-25T02:15:00+01:00", "2026-11-01T02:15:00+01:00"}},
+
+		// The clocks go from 02:00 back to 01:30 on April 5th, and from 02:00 to
+		// 02:30 on October 4th 2026 on Lord Howe Island.
+		{"Australia/Lord_Howe", "0 45 1 * * *", DSTSkipMissing, "2026-04-04T12:00:00+11:00",
+			[]string{"2026-04-05T01:45:00+11:00", "2026-04-05T01:45:00+10:30", "2026-04-06T01:45:00+10:30"}},
+		{"Australia/Lord_Howe", "0 45 1 * * *", DSTSecondOccurrence, "2026-04-04T12:00:00+11:00",
+			[]string{"2026-04-05T01:45:00+10:30", "2026-04-06T01:45:00+10:30"}},
+		{"Australia/Lord_Howe", "0 15 2 * * *", DSTSkipMissing, "2026-10-03T12:00:00+10:30",
+			[]string{"2026-10-05T02:15:00+11:00"}},
+		{"Australia/Lord_Howe", "0 15 2 * * *", DSTShiftMissing, "2026-10-03T12:00:00+10:30",
+			[]string{"2026-10-04T02:30:00+11:00", "2026-10-05T02:15:00+11:00"}},
+	}
+
+	for _, c := range tests {
+		loc, err := time.LoadLocation(c.zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := sched.(*SpecSchedule)
+		s.DST = c.policy
+
+		from, _ := time.Parse(time.RFC3339, c.from)
+		var expected []time.Time
+		for _, e := range c.expected {
+			et, _ := time.Parse(time.RFC3339, e)
+			expected = append(expected, et)
+		}
+
+		// Next gives the expected times in order, and Prev in reverse order.
+		next := from.In(loc)
+		for i, e := range expected {
+			next = s.Next(next)
+			if !next.Equal(e) {
+				t.Errorf("%s %q %d, Next #%d: (expected) %v != %v (actual)", c.zone, c.spec, c.policy, i, e, next)
+				break
+			}
+		}
+		prev := expected[len(expected)-1].In(loc)
+		for i := len(expected) - 2; i >= 0; i-- {
+			prev = s.Prev(prev)
+			if !prev.Equal(expected[i]) {
+				t.Errorf("%s %q %d, Prev #%d: (expected) %v != %v (actual)", c.zone, c.spec, c.policy, i, expected[i], prev)
+				break
+			}
+		}
+	}
+}
+
+// TestDSTNextAdvances checks that Next always returns later times, and Prev
+// earlier ones, around the transitions of a few time zones.
+func TestDSTNextAdvances(t *testing.T) {
+	specs := []string{"* * * * * *", "0 */15 * * * *", "0 30 1,2 * * *", "0 0 0-3 * * *"}
+	policies := []DSTPolicy{DSTSkipMissing, DSTShiftMissing | DSTFirstOccurrence, DSTSecondOccurrence}
+	for _, zone := range []string{"America/New_York", "Europe/Paris", "Australia/Lord_Howe", "America/Santiago"} {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, spec := range specs {
+			sched, err := Parse("CRON_TZ=" + zone + " " + spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := sched.(*SpecSchedule)
+			for _, policy := range policies {
+				s.DST = policy
+				for start := time.Date(2026, time.January, 1, 0, 0, 0, 0, loc); start.Year() == 2026; start = start.AddDate(0, 0, 3) {
+					if _, end := start.ZoneBounds(); end.Sub(start) > 48*time.Hour {
+						continue
+					}
+					// Around a transition.
+					n, p := start, start.AddDate(0, 0, 3)
+					for i := 0; i < 200; i++ {
+						next := s.Next(n)
+						if !next.After(n) {
+							t.Fatalf("%s %q %d: Next(%v) = %v", zone, spec, policy, n, next)
+						}
+						n = next
+						prev := s.Prev(p)
+						if !prev.Before(p) {
+							t.Fatalf("%s %q %d: Prev(%v) = %v", zone, spec, policy, p, prev)
+						}
+						p = prev
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package etcdcron
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eventBridgeFields are the names of the fields of an EventBridge cron
+// expression, by position.
+var eventBridgeFields = []string{"minute", "hour", "day of month", "month", "day of week", "year"}
+
+// ParseEventBridge returns the schedule of the given AWS EventBridge schedule
+// expression:
+//   - "cron(minute hour dom month dow year)", e.g. "cron(0 12 ? * MON-FRI *)",
+//     where exactly one of the day-of-month and day-of-week fields is "?", the
+//     days of the week are numbered from 1 (Sunday) to 7 (Saturday), and "L",
+//     "W" and "#" are used as in the cron expressions of Parse. The schedule is
+//     evaluated in UTC, as EventBridge does.
+//   - "rate(value unit)", e.g. "rate(5 minutes)", where the unit is minutes,
+//     hours or days. The activation times are aligned on the Unix epoch, as
+//     the "@every" descriptor.
+//
+// It returns a *ParseError if the expression is not valid.
+func ParseEventBridge(spec string) (Schedule, error) {
+	schedule, err := parseEventBridge(spec)
+	if err != nil {
+		err.Spec = spec
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func parseEventBridge(spec string) (Schedule, *ParseError) {
+	switch {
+	case strings.HasPrefix(spec, "cron(") && strings.HasSuffix(spec, ")"):
+		schedule, err := parseEventBridgeCron(spec[len("cron(") : len(spec)-1])
+		if err != nil {
+			return nil, err
+		}
+		return schedule, nil
+	case strings.HasPrefix(spec, "rate(") && strings.HasSuffix(spec, ")"):
+		return parseEventBridgeRate(spec[len("rate(") : len(spec)-1])
+	}
+	return nil, parseErrorf(ErrInvalidValue, spec, "expected cron(...) or rate(...)")
+}
+
+func parseEventBridgeCron(expr string) (*SpecSchedule, *ParseError) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(eventBridgeFields) {
+		return nil, parseErrorf(ErrFieldCount, "", "expected %d, found %d", len(eventBridgeFields), len(fields))
+	}
+	if (fields[2] == "?") == (fields[4] == "?") {
+		return nil, parseErrorf(ErrInvalidValue, fields[2]+" "+fields[4], "exactly one of the day-of-month and day-of-week fields must be ?")
+	}
+
+	schedule := &SpecSchedule{Second: 1 << seconds.min, Location: time.UTC}
+	var err *ParseError
+	for i, field := range fields {
+		switch i {
+		case 0:
+			schedule.Minute, err = getField(field, minutes, 0)
+		case 1:
+			schedule.Hour, err = getField(field, hours, 0)
+		case 2:
+			schedule.Dom, schedule.DomLast, schedule.DomWeekday, err = getDomField(field, 0)
+		case 3:
+			schedule.Month, err = getField(field, months, 0)
+		case 4:
+			var dowField string
+			if dowField, err = eventBridgeDow(field); err == nil {
+				schedule.Dow, schedule.DowLast, schedule.DowNth, err = getDowField(dowField, 0)
+			}
+		case 5:
+			schedule.Years, err = getYearField(field)
+		}
+		for _, item := range strings.Split(field, ",") {
+			if err == nil && strings.HasPrefix(item, "H") {
+				err = parseErrorf(ErrInvalidValue, item, "hashed values are not allowed")
+			}
+		}
+		if err != nil {
+			err.Field = eventBridgeFields[i]
+			err.Index = i
+			return nil, err
+		}
+	}
+	return schedule, nil
+}
+
+// eventBridgeDow converts the given EventBridge day-of-week field, where the
+// days are numbered from 1 (Sunday) to 7 (Saturday), to the numbering of
+// Parse, from 0 (Sunday) to 6 (Saturday). "L" alone is the last day of the
+// week, Saturday.
+func eventBridgeDow(field string) (string, *ParseError) {
+	// convert converts a day, given as a number or a name.
+	convert := func(day string) (string, *ParseError) {
+		n, err := strconv.Atoi(day)
+		if err != nil {
+			return day, nil
+		}
+		if n < 1 || n > 7 {
+			return "", parseErrorf(ErrOutOfRange, day, "day of week not in 1-7")
+		}
+		return strconv.Itoa(n - 1), nil
+	}
+
+	items := strings.Split(field, ",")
+	for i, item := range items {
+		var err *ParseError
+		switch upper := strings.ToUpper(item); {
+		case upper == "L":
+			items[i] = "6"
+		case len(item) > 1 && strings.HasSuffix(upper, "L"):
+			items[i], err = convert(item[:len(item)-1])
+			items[i] += "L"
+		case strings.Contains(item, "#"):
+			dayAndNth := strings.SplitN(item, "#", 2)
+			items[i], err = convert(dayAndNth[0])
+			items[i] += "#" + dayAndNth[1]
+		default:
+			// A range with an optional step, which is not a day.
+			rangeAndStep := strings.SplitN(item, "/", 2)
+			days := strings.Split(rangeAndStep[0], "-")
+			for j := range days {
+				if days[j], err = convert(days[j]); err != nil {
+					break
+				}
+			}
+			items[i] = strings.Join(days, "-")
+			if len(rangeAndStep) == 2 {
+				items[i] += "/" + rangeAndStep[1]
+			}
+		}
+		if err != nil {
+			err.Token = item
+			return "", err
+		}
+	}
+	return strings.Join(items, ","), nil
+}
+
+// eventBridgeUnits are the units of the rate expressions.
+var eventBridgeUnits = map[string]time.Duration{
+	"minute":  time.Minute,
+	"minutes": time.Minute,
+	"hour":    time.Hour,
+	"hours":   time.Hour,
+	"day":     24 * time.Hour,
+	"days":    24 * time.Hour,
+}
+
+func parseEventBridgeRate(expr string) (Schedule, *ParseError) {
+	fields := strings.Fields(expr)
+	if len(fields) != 2 {
+		return nil, parseErrorf(ErrInvalidValue, expr, "expected a value and a unit")
+	}
+	value, err := strconv.Atoi(fields[0])
+	if err != nil || value < 1 {
+		return nil, parseErrorf(ErrBadDuration, fields[0], "expected a positive number")
+	}
+	unit, ok := eventBridgeUnits[strings.ToLower(fields[1])]
+	if !ok {
+		return nil, parseErrorf(ErrBadDuration, fields[1], "expected minutes, hours or days")
+	}
+	if time.Duration(value) > math.MaxInt64/unit {
+		return nil, parseErrorf(ErrBadDuration, expr, "%s %s is too long", fields[0], fields[1])
+	}
+	return EveryFrom(time.Duration(value)*unit, unixEpoch), nil
+}
@@ -0,0 +1,86 @@
+package etcdcron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseEventBridge(t *testing.T) {
+	tests := []struct {
+		spec     string
+		time     string
+		expected string
+	}{
+		{"cron(0 12 ? * MON-FRI *)", "2026-10-17T10:00:00Z", "2026-10-19T12:00:00Z"},
+		{"cron(0 12 ? * 2-6 *)", "2026-10-17T10:00:00Z", "2026-10-19T12:00:00Z"},
+		{"cron(0/15 * ? * 1 *)", "2026-10-17T10:00:00Z", "2026-10-18T00:00:00Z"},
+		{"cron(0 18 ? * L *)", "2026-10-17T10:00:00Z", "2026-10-17T18:00:00Z"},
+		{"cron(15 10 ? * 6L 2026-2027)", "2026-10-17T10:00:00Z", "2026-10-30T10:15:00Z"},
+		{"cron(0 8 ? * 2#1 *)", "2026-10-17T10:00:00Z", "2026-11-02T08:00:00Z"},
+		{"cron(0 8 ? * MON#1,FRIL *)", "2026-10-17T10:00:00Z", "2026-10-30T08:00:00Z"},
+		{"cron(0 9 L * ? *)", "2026-10-17T10:00:00Z", "2026-10-31T09:00:00Z"},
+		{"cron(0 9 15W NOV ? *)", "2026-10-17T10:00:00Z", "2026-11-16T09:00:00Z"},
+		{"cron(0 9 1 1 ? 2030)", "2026-10-17T10:00:00Z", "2030-01-01T09:00:00Z"},
+		{"CRON_TZ=Europe/Paris cron(0 12 ? * MON-FRI *)", "2026-10-17T10:00:00Z", "2026-10-19T10:00:00Z"},
+		{"rate(5 minutes)", "2026-10-17T10:02:30Z", "2026-10-17T10:05:00Z"},
+		{"rate(1 hour)", "2026-10-17T10:02:30Z", "2026-10-17T11:00:00Z"},
+		{"rate(1 day)", "2026-10-17T10:02:30Z", "2026-10-18T00:00:00Z"},
+		{"rate(106751 days)", "2026-10-17T10:02:30Z", "2262-04-11T00:00:00Z"},
+	}
+
+	for _, c := range tests {
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		from, _ := time.Parse(time.RFC3339, c.time)
+		expected, _ := time.Parse(time.RFC3339, c.expected)
+		if actual := sched.Next(from); !actual.Equal(expected) {
+			t.Errorf("%s, %s: (expected) %v != %v (actual)", c.spec, c.time, expected, actual)
+		}
+	}
+}
+
+func TestParseEventBridgeErrors(t *testing.T) {
+	errs := []struct {
+		spec  string
+		field string
+		token string
+		kind  error
+	}{
+		{"cron(0 12 * * MON-FRI *)", "", "* MON-FRI", ErrInvalidValue},
+		{"cron(0 12 ? * ? *)", "", "? ?", ErrInvalidValue},
+		{"cron(0 12 * * ?)", "", "", ErrFieldCount},
+		{"cron(0 12 ? * 8 *)", "day of week", "8", ErrOutOfRange},
+		{"cron(0 12 ? * 0#2 *)", "day of week", "0#2", ErrOutOfRange},
+		{"cron(60 12 * * ? *)", "minute", "60", ErrOutOfRange},
+		{"cron(H 12 * * ? *)", "minute", "H", ErrInvalidValue},
+		{"rate(0 minutes)", "", "0", ErrBadDuration},
+		{"rate(5 weeks)", "", "weeks", ErrBadDuration},
+		{"rate(5)", "", "5", ErrInvalidValue},
+		{"rate(106752 days)", "", "106752 days", ErrBadDuration},
+		{"cron(0 12 ? * MON-FRI *", "", "cron(0 12 ? * MON-FRI *", ErrInvalidValue},
+	}
+
+	for _, c := range errs {
+		_, err := ParseEventBridge(c.spec)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q => (expected) a *ParseError != %v (actual)", c.spec, err)
+			continue
+		}
+		if perr.Field != c.field || perr.Token != c.token || !errors.Is(err, c.kind) {
+			t.Errorf("%q => (expected) %q, %q, %v != %q, %q, %v (actual)", c.spec, c.field, c.token, c.kind, perr.Field, perr.Token, perr.Kind)
+		}
+	}
+
+	parser, err := NewParser(WithEventBridge(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.Parse("rate(5 minutes)"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("(expected) ErrInvalidValue != %v (actual)", err)
+	}
+}
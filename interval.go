@@ -0,0 +1,190 @@
+package etcdcron
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IntervalSchedule represents an ISO 8601 repeating interval, e.g. "5 times
+// every 90 minutes, starting at 2026-11-01T03:00:00Z". Contrary to
+// ConstantDelaySchedule, its activation times don't depend on the time the
+// Cron was started.
+type IntervalSchedule struct {
+	// Start is the first activation time.
+	Start time.Time
+	// Interval is the duration between two activations.
+	Interval time.Duration
+	// Count is the number of activations, 0 if unlimited.
+	Count int
+}
+
+// ParseInterval returns the schedule of the given ISO 8601 repeating interval:
+// "R[n]/<start>/<duration>" or "R[n]/<start>/<end>", e.g.
+// "R5/2026-11-01T03:00:00Z/PT90M". The start and end are RFC 3339 times, and the
+// duration is made of weeks, days, hours, minutes and seconds (e.g. "P1DT12H"),
+// years and months having no fixed duration. Without n, the repetitions are
+// unlimited.
+//
+// It returns a *ParseError if the interval is not valid.
+func ParseInterval(spec string) (*IntervalSchedule, error) {
+	schedule, err := parseInterval(spec)
+	if err != nil {
+		err.Spec = spec
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func parseInterval(spec string) (*IntervalSchedule, *ParseError) {
+	parts := strings.Split(spec, "/")
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "R") {
+		return nil, parseErrorf(ErrInvalidValue, spec, "expected R[n]/<start>/<duration>")
+	}
+
+	schedule := &IntervalSchedule{}
+	if n := parts[0][1:]; n != "" {
+		count, err := strconv.Atoi(n)
+		if err != nil {
+			return nil, parseErrorf(ErrInvalidValue, parts[0], "not a number of repetitions")
+		}
+		if count < 1 {
+			return nil, parseErrorf(ErrOutOfRange, parts[0], "at least one repetition is required")
+		}
+		schedule.Count = count
+	}
+
+	start, err := time.Parse(time.RFC3339, parts[1])
+	if err != nil {
+		return nil, parseErrorf(ErrInvalidValue, parts[1], "expected an RFC 3339 time")
+	}
+	schedule.Start = start.Truncate(time.Second)
+
+	if strings.HasPrefix(parts[2], "P") {
+		var perr *ParseError
+		schedule.Interval, perr = parseISODuration(parts[2])
+		if perr != nil {
+			return nil, perr
+		}
+	} else {
+		end, err := time.Parse(time.RFC3339, parts[2])
+		if err != nil {
+			return nil, parseErrorf(ErrInvalidValue, parts[2], "expected an RFC 3339 time or an ISO 8601 duration")
+		}
+		schedule.Interval = end.Truncate(time.Second).Sub(schedule.Start)
+	}
+	if schedule.Interval < time.Second {
+		return nil, parseErrorf(ErrBadDuration, parts[2], "the interval must be at least one second")
+	}
+
+	return schedule, nil
+}
+
+// parseISODuration parses an ISO 8601 duration made of weeks, days, hours,
+// minutes and seconds, e.g. "P1W", "PT90M" or "P1DT12H".
+func parseISODuration(expr string) (time.Duration, *ParseError) {
+	units := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour,
+		'D': 24 * time.Hour,
+	}
+	timeUnits := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+	}
+
+	var duration time.Duration
+	value := ""
+	for i := 1; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case c >= '0' && c <= '9':
+			value += string(c)
+		case c == 'T':
+			if value != "" {
+				return 0, parseErrorf(ErrBadDuration, expr, "missing unit")
+			}
+			units = timeUnits
+		default:
+			unit, ok := units[c]
+			if !ok {
+				return 0, parseErrorf(ErrBadDuration, expr, "unsupported unit %q", string(c))
+			}
+			if value == "" {
+				return 0, parseErrorf(ErrBadDuration, expr, "missing value")
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || time.Duration(n) > math.MaxInt64/unit {
+				return 0, parseErrorf(ErrBadDuration, expr, "%s%s is too long", value, string(c))
+			}
+			if duration += time.Duration(n) * unit; duration < 0 {
+				return 0, parseErrorf(ErrBadDuration, expr, "too long")
+			}
+			value = ""
+		}
+	}
+	if value != "" {
+		return 0, parseErrorf(ErrBadDuration, expr, "missing unit")
+	}
+	return duration, nil
+}
+
+// Next returns the first activation time later than the given time, or the
+// zero time if all the repetitions are over.
+func (schedule *IntervalSchedule) Next(t time.Time) time.Time {
+	if t.Before(schedule.Start) {
+		return schedule.Start.In(t.Location())
+	}
+	n := int(t.Sub(schedule.Start)/schedule.Interval) + 1
+	if schedule.Count > 0 && n >= schedule.Count {
+		return time.Time{}
+	}
+	return schedule.Start.Add(time.Duration(n) * schedule.Interval).In(t.Location())
+}
+
+// String returns the ISO 8601 repeating interval of the schedule, e.g.
+// "R5/2026-11-01T03:00:00Z/PT1H30M".
+func (schedule *IntervalSchedule) String() string {
+	count := ""
+	if schedule.Count > 0 {
+		count = strconv.Itoa(schedule.Count)
+	}
+	return "R" + count + "/" + schedule.Start.Format(time.RFC3339) + "/" + formatISODuration(schedule.Interval)
+}
+
+// MarshalText implements encoding.TextMarshaler, using String.
+func (schedule *IntervalSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, using Parse.
+func (schedule *IntervalSchedule) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	interval, ok := parsed.(*IntervalSchedule)
+	if !ok {
+		return unexpectedScheduleError(text, parsed)
+	}
+	*schedule = *interval
+	return nil
+}
+
+// formatISODuration returns the given duration, truncated to the second, as an
+// ISO 8601 duration in hours, minutes and seconds, e.g. "PT1H30M".
+func formatISODuration(d time.Duration) string {
+	h, m, s := int64(d/time.Hour), int64(d/time.Minute%60), int64(d/time.Second%60)
+	result := "PT"
+	if h > 0 {
+		result += strconv.FormatInt(h, 10) + "H"
+	}
+	if m > 0 {
+		result += strconv.FormatInt(m, 10) + "M"
+	}
+	if s > 0 || h == 0 && m == 0 {
+		result += strconv.FormatInt(s, 10) + "S"
+	}
+	return result
+}
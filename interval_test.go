@@ -0,0 +1,72 @@
+package etcdcron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIntervalNext(t *testing.T) {
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		{"2026-10-17T10:00:00Z", "R5/2026-11-01T03:00:00Z/PT90M", "2026-11-01T03:00:00Z"},
+		{"2026-11-01T03:00:00Z", "R5/2026-11-01T03:00:00Z/PT90M", "2026-11-01T04:30:00Z"},
+		{"2026-11-01T04:29:59Z", "R5/2026-11-01T03:00:00Z/PT90M", "2026-11-01T04:30:00Z"},
+		{"2026-11-01T07:59:59Z", "R5/2026-11-01T03:00:00Z/PT90M", "2026-11-01T09:00:00Z"},
+		{"2026-11-01T09:00:00Z", "R5/2026-11-01T03:00:00Z/PT90M", ""},
+		{"2027-11-01T09:00:00Z", "R5/2026-11-01T03:00:00Z/PT90M", ""},
+		{"2027-11-01T09:00:00Z", "R/2026-11-01T03:00:00Z/PT90M", "2027-11-01T10:30:00Z"},
+		{"2026-11-01T03:00:00Z", "R2/2026-11-01T03:00:00Z/P1W", "2026-11-08T03:00:00Z"},
+		{"2026-11-01T03:00:00Z", "R/2026-11-01T03:00:00Z/P1DT12H", "2026-11-02T15:00:00Z"},
+		{"2026-11-01T03:00:00Z", "R/2026-11-01T03:00:00Z/PT1H30M15S", "2026-11-01T04:30:15Z"},
+		{"2026-11-01T03:00:00Z", "R3/2026-11-01T03:00:00Z/2026-11-01T03:20:00Z", "2026-11-01T03:20:00Z"},
+		{"2026-10-31T23:00:00-05:00", "R/2026-11-01T03:00:00+01:00/PT1H", "2026-11-01T05:00:00Z"},
+	}
+
+	for _, c := range runs {
+		s, err := Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		from, _ := time.Parse(time.RFC3339, c.time)
+		var expected time.Time
+		if c.expected != "" {
+			expected, _ = time.Parse(time.RFC3339, c.expected)
+		}
+		actual := s.Next(from)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, %s: (expected) %v != %v (actual)", c.spec, c.time, expected, actual)
+		}
+	}
+}
+
+func TestParseIntervalErrors(t *testing.T) {
+	errorCases := []struct {
+		spec string
+		kind error
+	}{
+		{"R5/2026-11-01T03:00:00Z", ErrInvalidValue},
+		{"Rx/2026-11-01T03:00:00Z/PT1H", ErrInvalidValue},
+		{"R0/2026-11-01T03:00:00Z/PT1H", ErrOutOfRange},
+		{"R5/2026-11-01/PT1H", ErrInvalidValue},
+		{"R5/2026-11-01T03:00:00Z/P1M", ErrBadDuration},
+		{"R5/2026-11-01T03:00:00Z/PT", ErrBadDuration},
+		{"R5/2026-11-01T03:00:00Z/PT1", ErrBadDuration},
+		{"R5/2026-11-01T03:00:00Z/PTH", ErrBadDuration},
+		{"R5/2026-11-01T03:00:00Z/PT99999999999999999999H", ErrBadDuration},
+		{"R5/2026-11-01T03:00:00Z/PT9999999H", ErrBadDuration},
+		{"R5/2026-11-01T03:00:00Z/P15249WT999999S", ErrBadDuration},
+		{"R5/2026-11-01T03:00:00Z/2026-11-01T02:00:00Z", ErrBadDuration},
+	}
+
+	for _, c := range errorCases {
+		_, err := ParseInterval(c.spec)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || !errors.Is(err, c.kind) || parseErr.Spec != c.spec {
+			t.Errorf("%s: unexpected error %v", c.spec, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package etcdcron
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"time"
+)
+
+// JitterSchedule delays the activation times of a schedule by a pseudo-random
+// duration derived from the key and from the activation time, to spread the
+// load of the jobs.
+type JitterSchedule struct {
+	Schedule Schedule
+	// Max is the maximum delay, excluded. The delays are whole milliseconds.
+	Max time.Duration
+	// Key, usually the name of the job, makes the delays of the schedules
+	// given the same activation times differ.
+	Key string
+}
+
+// Jitter returns a Schedule activated at the activation times of the given
+// schedule, delayed by less than max. Its key is empty: Cron.Schedule sets it
+// to the name of the job, so that the delays of the jobs differ.
+func Jitter(schedule Schedule, max time.Duration) *JitterSchedule {
+	return &JitterSchedule{Schedule: schedule, Max: max}
+}
+
+// String returns the schedule in the form of the call to Jitter, without the
+// key, e.g. "Jitter(0 0 * * * *; 30s)". It can't be parsed.
+func (j *JitterSchedule) String() string {
+	return "Jitter(" + scheduleString(j.Schedule) + "; " + j.Max.String() + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler, but always returns an error:
+// the String of the schedule can't be parsed.
+func (j *JitterSchedule) MarshalText() ([]byte, error) {
+	return nil, unparsableError(j)
+}
+
+// Next returns the next delayed activation time later than the given time, or
+// the zero time if there is none or it couldn't be found in a reasonable
+// number of steps.
+func (j *JitterSchedule) Next(t time.Time) time.Time {
+	// The activation times delayed after t are later than t - Max, and the
+	// delays may change their order.
+	var next time.Time
+	activation := j.Schedule.Next(t.Add(-j.Max))
+	for step := 0; step < maxCombinatorSteps && !activation.IsZero(); step++ {
+		if !next.IsZero() && !activation.Before(next) {
+			break
+		}
+		if delayed := activation.Add(j.Delay(activation)); delayed.After(t) && (next.IsZero() || delayed.Before(next)) {
+			next = delayed
+		}
+		activation = j.Schedule.Next(activation)
+	}
+	return next
+}
+
+// Delay returns the delay of the given activation time of the schedule, a whole
+// number of milliseconds less than Max.
+func (j *JitterSchedule) Delay(activation time.Time) time.Duration {
+	max := uint64(j.Max / time.Millisecond)
+	if max == 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(j.Key))
+	var unixMilli [8]byte
+	binary.BigEndian.PutUint64(unixMilli[:], uint64(activation.UnixNano()/int64(time.Millisecond)))
+	h.Write(unixMilli[:])
+	return time.Duration(h.Sum64()%max) * time.Millisecond
+}
@@ -0,0 +1,96 @@
+package etcdcron
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestJitterDelay(t *testing.T) {
+	j := &JitterSchedule{Max: 30 * time.Second, Key: "job"}
+	other := &JitterSchedule{Max: 30 * time.Second, Key: "other_job"}
+
+	start := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	differs := false
+	for i := 0; i < 100; i++ {
+		activation := start.Add(time.Duration(i) * time.Minute)
+		delay := j.Delay(activation)
+		if delay < 0 || delay >= j.Max || delay%time.Millisecond != 0 {
+			t.Fatalf("%v: unexpected delay %v", activation, delay)
+		}
+		// The delay only depends on the key and the activation time.
+		if again := (&JitterSchedule{Max: 30 * time.Second, Key: "job"}).Delay(activation.In(time.Local)); again != delay {
+			t.Fatalf("%v: (expected) %v != %v (actual)", activation, delay, again)
+		}
+		if other.Delay(activation) != delay {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("expected the delays of different keys to differ")
+	}
+
+	// The delays under a second are in milliseconds.
+	short := &JitterSchedule{Max: 500 * time.Millisecond, Key: "job"}
+	delayed := false
+	for i := 0; i < 10; i++ {
+		delay := short.Delay(start.Add(time.Duration(i) * time.Second))
+		if delay < 0 || delay >= short.Max || delay%time.Millisecond != 0 {
+			t.Fatalf("unexpected delay %v", delay)
+		}
+		delayed = delayed || delay > 0
+	}
+	if !delayed {
+		t.Error("expected delays under a second")
+	}
+	if delay := (&JitterSchedule{Max: time.Microsecond}).Delay(start); delay != 0 {
+		t.Errorf("expected no delay under a millisecond, got %v", delay)
+	}
+}
+
+func TestJitterString(t *testing.T) {
+	sched, err := Parse("0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, expected := Jitter(sched, 30*time.Second).String(), "Jitter(0 0 * * * *; 30s)"; actual != expected {
+		t.Errorf("(expected) %q != %q (actual)", expected, actual)
+	}
+}
+
+func TestJitterNext(t *testing.T) {
+	for _, spec := range []string{"0 * * * * *", "*/10 * * * * *", "0 0 * * * *"} {
+		sched, err := Parse(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		j := Jitter(sched, 30*time.Second)
+		j.Key = "job"
+
+		// The delayed activation times, sorted.
+		start := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+		var expected []time.Time
+		for _, activation := range Occurrences(sched, start.Add(-j.Max), start.Add(3*time.Hour)) {
+			if delayed := activation.Add(j.Delay(activation)); delayed.After(start) {
+				expected = append(expected, delayed)
+			}
+		}
+		sort.Slice(expected, func(a, b int) bool { return expected[a].Before(expected[b]) })
+
+		next := start
+		for i := 0; i < len(expected)-10; i++ {
+			next = j.Next(next)
+			for i+1 < len(expected) && expected[i+1].Equal(expected[i]) {
+				// Activation times delayed to the same time are activated once.
+				expected = append(expected[:i], expected[i+1:]...)
+			}
+			if !next.Equal(expected[i]) {
+				t.Fatalf("%s #%d: (expected) %v != %v (actual)", spec, i, expected[i], next)
+			}
+		}
+	}
+
+	if next := Jitter(Once(time.Now().Add(-time.Hour)), time.Minute).Next(time.Now()); !next.IsZero() {
+		t.Errorf("expected the zero time, got %v", next)
+	}
+}
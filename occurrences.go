@@ -0,0 +1,29 @@
+package etcdcron
+
+import "time"
+
+// NextN returns the next n activation times of the schedule, later than the
+// given time. Fewer times are returned if the schedule is exhausted.
+func NextN(schedule Schedule, t time.Time, n int) []time.Time {
+	var times []time.Time
+	for i := 0; i < n; i++ {
+		t = schedule.Next(t)
+		if t.IsZero() {
+			break
+		}
+		times = append(times, t)
+	}
+	return times
+}
+
+// Occurrences returns the activation times of the schedule later than from and
+// not later than to, e.g. to find the runs missed during a downtime. The
+// caller is responsible for choosing a range of a reasonable size for the
+// schedule.
+func Occurrences(schedule Schedule, from, to time.Time) []time.Time {
+	var times []time.Time
+	for t := schedule.Next(from); !t.IsZero() && !t.After(to); t = schedule.Next(t) {
+		times = append(times, t)
+	}
+	return times
+}
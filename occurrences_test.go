@@ -0,0 +1,71 @@
+package etcdcron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextN(t *testing.T) {
+	sched, err := Parse("0 0 9,17 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := NextN(sched, getTime("Fri Jul 13 12:00 2012"), 3)
+	expected := []time.Time{getTime("Fri Jul 13 17:00 2012"), getTime("Mon Jul 16 09:00 2012"), getTime("Mon Jul 16 17:00 2012")}
+	if len(actual) != len(expected) {
+		t.Fatalf("(expected) %v != %v (actual)", expected, actual)
+	}
+	for i := range expected {
+		if !actual[i].Equal(expected[i]) {
+			t.Errorf("%d: (expected) %v != %v (actual)", i, expected[i], actual[i])
+		}
+	}
+
+	// An exhausted schedule returns fewer times.
+	if actual := NextN(Once(getTime("Fri Jul 13 17:00 2012")), getTime("Fri Jul 13 12:00 2012"), 3); len(actual) != 1 {
+		t.Errorf("expected 1 time, got %v", actual)
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	sched, err := Parse("0 0 9,17 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		from, to string
+		expected []string
+	}{
+		{"Fri Jul 13 12:00 2012", "Mon Jul 16 17:00 2012", []string{"Fri Jul 13 17:00 2012", "Mon Jul 16 09:00 2012", "Mon Jul 16 17:00 2012"}},
+		{"Fri Jul 13 17:00 2012", "Mon Jul 16 08:59 2012", nil},
+		{"Fri Jul 13 17:00 2012", "Fri Jul 13 12:00 2012", nil},
+	}
+
+	for _, c := range tests {
+		actual := Occurrences(sched, getTime(c.from), getTime(c.to))
+		if len(actual) != len(c.expected) {
+			t.Errorf("%s - %s: (expected) %v != %v (actual)", c.from, c.to, c.expected, actual)
+			continue
+		}
+		for i := range c.expected {
+			if !actual[i].Equal(getTime(c.expected[i])) {
+				t.Errorf("%s - %s, %d: (expected) %v != %v (actual)", c.from, c.to, i, c.expected[i], actual[i])
+			}
+		}
+	}
+}
+
+func BenchmarkOccurrences(b *testing.B) {
+	sched, err := Parse("0 */15 9-17 * * MON-FRI")
+	if err != nil {
+		b.Fatal(err)
+	}
+	from := time.Date(2026, time.October, 17, 10, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Occurrences(sched, from, to)
+	}
+}
@@ -0,0 +1,354 @@
+package etcdcron
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CalendarEventSchedule is a schedule described by a systemd calendar event
+// expression, as used by the OnCalendar= setting of the timer units, e.g.
+// "Mon..Fri *-*-* 09:00:00".
+//
+// It is stored as a SpecSchedule, except that the day-of-week and the
+// day-of-month restrictions must both be satisfied, as systemd does.
+type CalendarEventSchedule struct {
+	SpecSchedule
+}
+
+// calendarShorthands are the expressions equivalent to the systemd shorthands.
+var calendarShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+// calendarWeekdays are the full names of the days of week, which systemd
+// accepts besides their abbreviations.
+var calendarWeekdays = map[string]uint{
+	"sunday":    0,
+	"monday":    1,
+	"tuesday":   2,
+	"wednesday": 3,
+	"thursday":  4,
+	"friday":    5,
+	"saturday":  6,
+}
+
+// ParseCalendarEvent returns the schedule described by the given systemd
+// calendar event expression: "[weekdays] [[year-]month-day] [hour:minute[:second]] [time zone]".
+//
+// Each component is a comma-separated list of values, "*", ranges such as
+// "Mon..Fri" or "1..5", and repetitions such as "*/15" or "1..10/2". In the
+// day, "~" counts from the end of the month: "*-02~03" is the third last day of
+// February. The date defaults to every day and the time to midnight, and the
+// shorthands "minutely", "hourly", "daily", "weekly", "monthly", "yearly",
+// "quarterly" and "semiannually" are accepted.
+//
+// It returns a *ParseError if the expression is not valid.
+func ParseCalendarEvent(expr string) (*CalendarEventSchedule, error) {
+	schedule, err := parseCalendarEvent(expr)
+	if err != nil {
+		err.Spec = expr
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func parseCalendarEvent(expr string) (*CalendarEventSchedule, *ParseError) {
+	tokens := strings.Fields(expr)
+	if len(tokens) == 0 {
+		return nil, parseErrorf(ErrEmptySpec, "", "")
+	}
+
+	if shorthand, ok := calendarShorthands[strings.ToLower(tokens[0])]; ok {
+		tokens = append(strings.Fields(shorthand), tokens[1:]...)
+	}
+
+	var weekdays, date, clock, zone string
+	for i, token := range tokens {
+		switch {
+		case strings.Contains(token, ":"):
+			if clock != "" {
+				return nil, parseErrorf(ErrFieldCount, token, "more than one time")
+			}
+			clock = token
+		case token[0] == '*' || token[0] >= '0' && token[0] <= '9':
+			if date != "" || clock != "" {
+				return nil, parseErrorf(ErrFieldCount, token, "unexpected date")
+			}
+			date = token
+		case i == 0:
+			weekdays = token
+		case i == len(tokens)-1:
+			zone = token
+		default:
+			return nil, parseErrorf(ErrInvalidValue, token, "expected a date or a time")
+		}
+	}
+	if weekdays == "" {
+		weekdays = "*"
+	}
+	if date == "" {
+		date = "*-*-*"
+	}
+	if clock == "" {
+		clock = "00:00:00"
+	}
+
+	schedule := &CalendarEventSchedule{}
+	s := &schedule.SpecSchedule
+
+	var err *ParseError
+	s.Dow, err = getCalendarField(weekdays, dow)
+	if err != nil {
+		err.Field = fieldNames[5]
+		return nil, err
+	}
+
+	// Split the date on its last separator, which may be the "~" of the days
+	// counted from the end of the month.
+	sep := strings.LastIndexAny(date, "-~")
+	if sep == -1 {
+		return nil, parseErrorf(ErrInvalidValue, date, "expected a date")
+	}
+	yearAndMonth, day := strings.Split(date[:sep], "-"), date[sep:]
+	if len(yearAndMonth) == 1 {
+		yearAndMonth = []string{"*", yearAndMonth[0]}
+	}
+	if len(yearAndMonth) != 2 {
+		return nil, parseErrorf(ErrInvalidValue, date, "expected a date")
+	}
+	if yearAndMonth[0] != "*" {
+		s.Years, err = getYearField(strings.Replace(yearAndMonth[0], "..", "-", -1))
+		if err != nil {
+			err.Field = fieldNames[6]
+			return nil, err
+		}
+	}
+	s.Month, err = getCalendarField(yearAndMonth[1], months)
+	if err != nil {
+		err.Field = fieldNames[4]
+		return nil, err
+	}
+	if day[0] == '~' {
+		s.DomLast, err = getCalendarLastDays(day[1:])
+	} else {
+		s.Dom, err = getCalendarField(day[1:], dom)
+	}
+	if err != nil {
+		err.Field = fieldNames[3]
+		return nil, err
+	}
+	// Both the day of month and the day of week must match.
+	s.Dom |= starBit
+
+	parts := strings.Split(clock, ":")
+	if len(parts) == 2 {
+		parts = append(parts, "00")
+	}
+	if len(parts) != 3 {
+		return nil, parseErrorf(ErrInvalidValue, clock, "expected hour:minute[:second]")
+	}
+	for i, r := range []bounds{hours, minutes, seconds} {
+		field := []*uint64{&s.Hour, &s.Minute, &s.Second}[i]
+		*field, err = getCalendarField(parts[i], r)
+		if err != nil {
+			err.Field = fieldNames[2-i]
+			return nil, err
+		}
+	}
+
+	if zone != "" {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, parseErrorf(ErrUnknownLocation, zone, "%v", err)
+		}
+		s.Location = loc
+	}
+
+	return schedule, nil
+}
+
+// getCalendarField returns the bits represented by a component of a calendar
+// event: a comma-separated list of "*", values and "a..b" ranges, with an
+// optional "/step" repetition.
+func getCalendarField(field string, r bounds) (uint64, *ParseError) {
+	var bits uint64
+	for _, expr := range strings.Split(field, ",") {
+		rangeAndStep := strings.Split(expr, "/")
+		if len(rangeAndStep) > 2 {
+			return 0, parseErrorf(ErrBadStep, expr, "too many slashes")
+		}
+
+		var start, end uint
+		var err *ParseError
+		lowAndHigh := strings.Split(rangeAndStep[0], "..")
+		switch {
+		case rangeAndStep[0] == "*":
+			start, end = r.min, r.max
+			bits |= starBit
+		case len(lowAndHigh) > 2:
+			return 0, parseErrorf(ErrBadRange, expr, "too many ranges")
+		default:
+			if start, err = parseCalendarValue(lowAndHigh[0], r); err != nil {
+				err.Token = expr
+				return 0, err
+			}
+			end = start
+			if len(lowAndHigh) == 2 {
+				if end, err = parseCalendarValue(lowAndHigh[1], r); err != nil {
+					err.Token = expr
+					return 0, err
+				}
+			} else if len(rangeAndStep) == 2 {
+				end = r.max
+			}
+		}
+
+		step := uint(1)
+		if len(rangeAndStep) == 2 {
+			if step, err = parseInt(rangeAndStep[1]); err != nil {
+				err.Token = expr
+				return 0, err
+			}
+			if step == 0 {
+				return 0, parseErrorf(ErrBadStep, expr, "step must be positive")
+			}
+		}
+		if start < r.min || end > r.max {
+			return 0, parseErrorf(ErrOutOfRange, expr, "not in %d-%d", r.min, r.max)
+		}
+		if start > end {
+			return 0, parseErrorf(ErrBadRange, expr, "beginning of range beyond end of range")
+		}
+		bits |= getBits(start, end, step)
+	}
+	return bits, nil
+}
+
+// parseCalendarValue parses a value of a calendar event component, which may
+// be a day of week name.
+func parseCalendarValue(expr string, r bounds) (uint, *ParseError) {
+	if r.names != nil {
+		if value, ok := calendarWeekdays[strings.ToLower(expr)]; ok {
+			return value, nil
+		}
+	}
+	return parseIntOrName(expr, r.names)
+}
+
+// getCalendarLastDays returns the DomLast bits represented by the days of a
+// calendar event counted from the end of the month, "~01" being the last day.
+// A "/step" repetition goes on up to the last day.
+func getCalendarLastDays(field string) (uint64, *ParseError) {
+	var bits uint64
+	for _, expr := range strings.Split(field, ",") {
+		rangeAndStep := strings.Split(expr, "/")
+		if len(rangeAndStep) > 2 {
+			return 0, parseErrorf(ErrBadStep, "~"+expr, "too many slashes")
+		}
+		n, err := parseInt(rangeAndStep[0])
+		if err != nil {
+			err.Token = "~" + expr
+			return 0, err
+		}
+		if n < 1 || n > 31 {
+			return 0, parseErrorf(ErrOutOfRange, "~"+expr, "not in 1-31")
+		}
+		if len(rangeAndStep) == 1 {
+			bits |= 1 << (n - 1)
+			continue
+		}
+		step, err := parseInt(rangeAndStep[1])
+		if err != nil {
+			err.Token = "~" + expr
+			return 0, err
+		}
+		if step == 0 {
+			return 0, parseErrorf(ErrBadStep, "~"+expr, "step must be positive")
+		}
+		// "~07/2" is the 7th, 5th, 3rd and last day from the end.
+		for i := int(n) - 1; i >= 0; i -= int(step) {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+// calendarWeekdayNames are the abbreviations of the days of week.
+var calendarWeekdayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// String returns the canonical expression of the schedule, prefixed by
+// "OnCalendar=" so that Parse accepts it, e.g.
+// "OnCalendar=Mon..Fri *-*-* 09:00:00". Parsing it gives back an equivalent
+// schedule, but with the Horizon and the DST policy of the parser: they are not
+// encoded.
+func (s *CalendarEventSchedule) String() string {
+	var tokens []string
+	if weekdays := formatCalendarField(s.Dow, dow, func(v uint) string { return calendarWeekdayNames[v] }); weekdays != "*" {
+		tokens = append(tokens, weekdays)
+	}
+
+	year := "*"
+	if s.Years != nil {
+		year = strings.Join(formatYears(s.Years, ".."), ",")
+	}
+	twoDigits := func(v uint) string { return fmt.Sprintf("%02d", v) }
+	date := year + "-" + formatCalendarField(s.Month, months, twoDigits)
+	if s.DomLast != 0 {
+		var days []string
+		for n := uint(0); n < 31; n++ {
+			if 1<<n&s.DomLast > 0 {
+				days = append(days, twoDigits(n+1))
+			}
+		}
+		date += "~" + strings.Join(days, ",")
+	} else {
+		date += "-" + formatCalendarField(s.Dom, dom, twoDigits)
+	}
+	tokens = append(tokens, date, formatCalendarField(s.Hour, hours, twoDigits)+":"+
+		formatCalendarField(s.Minute, minutes, twoDigits)+":"+
+		formatCalendarField(s.Second, seconds, twoDigits))
+
+	if s.Location != nil {
+		tokens = append(tokens, s.Location.String())
+	}
+	return "OnCalendar=" + strings.Join(tokens, " ")
+}
+
+// formatCalendarField returns a component of a calendar event representing
+// the given bits: "*" if they are all set, values and ranges otherwise.
+func formatCalendarField(bits uint64, r bounds, format func(uint) string) string {
+	bits &^= starBit
+	if bits == getBits(r.min, r.max, 1) {
+		return "*"
+	}
+	return strings.Join(formatRanges(bits, r.min, r.max, "..", format), ",")
+}
+
+// MarshalText implements encoding.TextMarshaler, using String.
+func (s *CalendarEventSchedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, using Parse: the Horizon
+// and the DST policy are the default ones.
+func (s *CalendarEventSchedule) UnmarshalText(text []byte) error {
+	schedule, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	event, ok := schedule.(*CalendarEventSchedule)
+	if !ok {
+		return unexpectedScheduleError(text, schedule)
+	}
+	*s = *event
+	return nil
+}
@@ -0,0 +1,123 @@
+package etcdcron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCalendarEventNext(t *testing.T) {
+	runs := []struct {
+		time, expr string
+		expected   string
+	}{
+		{"2026-10-17T10:00:00Z", "Mon..Fri *-*-* 09:00:00", "2026-10-19T09:00:00Z"},
+		{"2026-10-19T08:59:59Z", "Mon..Fri *-*-* 09:00:00", "2026-10-19T09:00:00Z"},
+		{"2026-10-17T10:00:00Z", "Sat,Sun 10:30", "2026-10-17T10:30:00Z"},
+		{"2026-10-17T10:00:00Z", "monday 8:00", "2026-10-19T08:00:00Z"},
+		{"2026-10-17T10:00:00Z", "*-*-01 04:00", "2026-11-01T04:00:00Z"},
+		{"2026-10-17T10:00:00Z", "11-01", "2026-11-01T00:00:00Z"},
+		{"2026-10-17T10:00:00Z", "*-*-1/10 12:00", "2026-10-21T12:00:00Z"},
+		{"2026-10-17T10:07:00Z", "*:0/15", "2026-10-17T10:15:00Z"},
+		{"2026-10-17T10:07:00Z", "*-*-* 10..12:00,30:00", "2026-10-17T10:30:00Z"},
+		{"2026-10-17T10:00:00Z", "2027..2028-06-01", "2027-06-01T00:00:00Z"},
+		{"2026-10-17T10:00:00Z", "2027-01-01 00:00 UTC", "2027-01-01T00:00:00Z"},
+
+		// Both the day of week and the day of month must match.
+		{"2026-01-01T00:00:00Z", "Fri *-*-13", "2026-02-13T00:00:00Z"},
+
+		// Days from the end of the month.
+		{"2026-10-17T10:00:00Z", "*-02~01", "2027-02-28T00:00:00Z"},
+		{"2026-10-17T10:00:00Z", "*-*~03", "2026-10-29T00:00:00Z"},
+		{"2026-10-30T10:00:00Z", "*-*~03/2", "2026-10-31T00:00:00Z"},
+		{"2026-10-17T10:00:00Z", "Mon *-*~07/1", "2026-10-26T00:00:00Z"},
+
+		// Shorthands.
+		{"2026-10-17T10:00:00Z", "minutely", "2026-10-17T10:01:00Z"},
+		{"2026-10-17T10:00:00Z", "hourly", "2026-10-17T11:00:00Z"},
+		{"2026-10-17T10:00:00Z", "daily", "2026-10-18T00:00:00Z"},
+		{"2026-10-17T10:00:00Z", "weekly", "2026-10-19T00:00:00Z"},
+		{"2026-10-17T10:00:00Z", "monthly", "2026-11-01T00:00:00Z"},
+		{"2026-10-17T10:00:00Z", "quarterly", "2027-01-01T00:00:00Z"},
+		{"2026-10-17T10:00:00Z", "semiannually", "2027-01-01T00:00:00Z"},
+		{"2026-10-17T10:00:00Z", "yearly", "2027-01-01T00:00:00Z"},
+
+		// Time zones.
+		{"2026-10-17T10:00:00Z", "*-*-* 09:00 Europe/Paris", "2026-10-18T07:00:00Z"},
+		{"2026-10-17T10:00:00Z", "daily Europe/Paris", "2026-10-17T22:00:00Z"},
+	}
+
+	for _, c := range runs {
+		s, err := ParseCalendarEvent(c.expr)
+		if err != nil {
+			t.Errorf("%s: %v", c.expr, err)
+			continue
+		}
+		from, _ := time.Parse(time.RFC3339, c.time)
+		expected, _ := time.Parse(time.RFC3339, c.expected)
+		actual := s.Next(from)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, %s: (expected) %v != %v (actual)", c.expr, c.time, expected, actual)
+		}
+	}
+}
+
+func TestParseOnCalendar(t *testing.T) {
+	from := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	runs := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"OnCalendar=Mon..Fri *-*-* 09:00:00", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=Europe/Paris OnCalendar=daily", time.Date(2026, 10, 17, 22, 0, 0, 0, time.UTC)},
+		{"OnCalendar=daily Europe/Paris", time.Date(2026, 10, 17, 22, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC OnCalendar=daily UTC", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range runs {
+		s, err := Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		if _, ok := s.(*CalendarEventSchedule); !ok {
+			t.Errorf("%s: unexpected schedule %T", c.spec, s)
+		}
+		if actual := s.Next(from); !actual.Equal(c.expected) {
+			t.Errorf("%s: (expected) %v != %v (actual)", c.spec, c.expected, actual)
+		}
+	}
+}
+
+func TestParseCalendarEventErrors(t *testing.T) {
+	errorCases := []struct {
+		expr  string
+		kind  error
+		field string
+	}{
+		{"", ErrEmptySpec, ""},
+		{"Funday 10:00", ErrInvalidValue, "day of week"},
+		{"Mon..Fri *-13-01", ErrOutOfRange, "month"},
+		{"Fri..Mon", ErrBadRange, "day of week"},
+		{"*-*-32", ErrOutOfRange, "day of month"},
+		{"*-*~0", ErrOutOfRange, "day of month"},
+		{"*-*-* 25:00", ErrOutOfRange, "hour"},
+		{"*-*-* 1/0:00", ErrBadStep, "hour"},
+		{"*-*-* 10", ErrFieldCount, ""},
+		{"*-*-* 10:00 10:30", ErrFieldCount, ""},
+		{"1969-01-01", ErrOutOfRange, "year"},
+		{"*-*-* 10:00 Mars/Olympus", ErrUnknownLocation, ""},
+	}
+
+	for _, c := range errorCases {
+		_, err := ParseCalendarEvent(c.expr)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%q: expected a *ParseError, got %v", c.expr, err)
+			continue
+		}
+		if !errors.Is(err, c.kind) || parseErr.Field != c.field || parseErr.Spec != c.expr {
+			t.Errorf("%q: unexpected error %#v", c.expr, parseErr)
+		}
+	}
+}
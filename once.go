@@ -0,0 +1,51 @@
+package etcdcron
+
+import "time"
+
+// OnceSchedule is activated only once, at the given time, e.g. "@at
+// 2026-11-01T03:00:00Z".
+type OnceSchedule struct {
+	At time.Time
+}
+
+// Once returns a Schedule that activates once, at the given time. Any fields
+// less than a Second are truncated.
+func Once(at time.Time) OnceSchedule {
+	return OnceSchedule{
+		At: at.Truncate(time.Second),
+	}
+}
+
+// Next returns the activation time if it is later than the given time, the
+// zero time otherwise.
+func (schedule OnceSchedule) Next(t time.Time) time.Time {
+	if !schedule.At.After(t) {
+		return time.Time{}
+	}
+	return schedule.At.In(t.Location())
+}
+
+// String returns the descriptor of the schedule, e.g.
+// "@at 2026-11-01T03:00:00Z".
+func (schedule OnceSchedule) String() string {
+	return "@at " + schedule.At.Format(time.RFC3339)
+}
+
+// MarshalText implements encoding.TextMarshaler, using String.
+func (schedule OnceSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, using Parse.
+func (schedule *OnceSchedule) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	once, ok := parsed.(OnceSchedule)
+	if !ok {
+		return unexpectedScheduleError(text, parsed)
+	}
+	*schedule = once
+	return nil
+}
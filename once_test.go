@@ -0,0 +1,41 @@
+package etcdcron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnceNext(t *testing.T) {
+	at := time.Date(2026, 11, 1, 3, 0, 0, 0, time.UTC)
+	tests := []struct {
+		time     time.Time
+		expected time.Time
+	}{
+		{at.Add(-time.Hour), at},
+		{at.Add(-time.Nanosecond), at},
+		{at, time.Time{}},
+		{at.Add(time.Hour), time.Time{}},
+	}
+
+	for _, c := range tests {
+		actual := Once(at.Add(500 * time.Millisecond)).Next(c.time)
+		if !actual.Equal(c.expected) {
+			t.Errorf("%s: (expected) %v != %v (actual)", c.time, c.expected, actual)
+		}
+	}
+}
+
+func TestParseAt(t *testing.T) {
+	s, err := Parse("@at 2026-11-01T04:00:00+01:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := time.Date(2026, 11, 1, 3, 0, 0, 0, time.UTC)
+	if actual := s.Next(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)); !actual.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, actual)
+	}
+
+	if _, err := Parse("@at tomorrow"); err == nil {
+		t.Error("expected an error parsing an invalid time")
+	}
+}
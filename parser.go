@@ -2,91 +2,692 @@ package etcdcron
 
 import (
 	"fmt"
-	"log"
+	"hash/fnv"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Kinds of parse errors, which can be matched with errors.Is.
+var (
+	ErrEmptySpec         = errors.New("empty spec")
+	ErrFieldCount        = errors.New("wrong number of fields")
+	ErrInvalidValue      = errors.New("invalid value")
+	ErrOutOfRange        = errors.New("value out of range")
+	ErrBadRange          = errors.New("invalid range")
+	ErrBadStep           = errors.New("invalid step")
+	ErrUnknownDescriptor = errors.New("unknown descriptor")
+	ErrBadDuration       = errors.New("invalid duration")
+	ErrUnknownLocation   = errors.New("unknown time zone")
+	ErrUnsatisfiable     = errors.New("schedule is never activated")
+	ErrTooRare           = errors.New("schedule is activated too rarely")
 )
 
+// fieldNames are the names of the fields of a spec, by index.
+var fieldNames = []string{"second", "minute", "hour", "day of month", "month", "day of week", "year"}
+
+// ParseError describes why a spec is not valid.
+type ParseError struct {
+	// Spec is the spec which failed to be parsed.
+	Spec string
+	// Field is the name of the field in error (e.g. "day of month"), empty if
+	// the error is not specific to a field.
+	Field string
+	// Index is the position of the field in the spec, starting at 0, or -1 if
+	// the error is not specific to a field.
+	Index int
+	// Token is the part of the spec in error.
+	Token string
+	// Kind is the kind of error, one of the Err* variables.
+	Kind error
+	// Detail gives additional information about the error, if any.
+	Detail string
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("invalid spec %q: ", e.Spec)
+	if e.Field != "" {
+		msg += e.Field + " field: "
+	}
+	msg += e.Kind.Error()
+	if e.Token != "" && e.Token != e.Spec {
+		msg += fmt.Sprintf(" %q", e.Token)
+	}
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the kind of the error.
+func (e *ParseError) Unwrap() error {
+	return e.Kind
+}
+
+// parseErrorf returns a ParseError about the given token, which is completed by
+// the caller with the spec and the field.
+func parseErrorf(kind error, token, format string, args ...interface{}) *ParseError {
+	return &ParseError{
+		Index:  -1,
+		Token:  token,
+		Kind:   kind,
+		Detail: fmt.Sprintf(format, args...),
+	}
+}
+
+// FieldMode tells whether a field of a spec is required, optional or not
+// accepted at all by a Parser.
+type FieldMode int
+
+const (
+	// FieldRequired fields must be present in the spec.
+	FieldRequired FieldMode = iota
+	// FieldOptional fields may be omitted, in which case they take their
+	// default value: 0 for the seconds, a star for the other fields.
+	FieldOptional
+	// FieldUnused fields are not accepted and always take their default value.
+	FieldUnused
+)
+
+// Parser parses specs into schedules, according to its options. The zero value
+// is not usable, use NewParser.
+type Parser struct {
+	seconds     FieldMode
+	dow         FieldMode
+	year        FieldMode
+	descriptors bool
+	eventBridge bool
+	horizon     int
+	dst         DSTPolicy
+	maxInterval time.Duration
+}
+
+// ParserOpt configures a Parser.
+type ParserOpt func(parser *Parser)
+
+// WithSecondsField sets whether the leading seconds field is required (the
+// default), optional or not accepted.
+func WithSecondsField(mode FieldMode) ParserOpt {
+	return ParserOpt(func(parser *Parser) {
+		parser.seconds = mode
+	})
+}
+
+// WithDowField sets whether the day-of-week field is required, optional (the
+// default) or not accepted.
+func WithDowField(mode FieldMode) ParserOpt {
+	return ParserOpt(func(parser *Parser) {
+		parser.dow = mode
+	})
+}
+
+// WithYearField sets whether the trailing year field is required, optional
+// (the default) or not accepted.
+func WithYearField(mode FieldMode) ParserOpt {
+	return ParserOpt(func(parser *Parser) {
+		parser.year = mode
+	})
+}
+
+// WithDescriptors sets whether descriptors such as "@daily" are accepted (the
+// default).
+func WithDescriptors(allowed bool) ParserOpt {
+	return ParserOpt(func(parser *Parser) {
+		parser.descriptors = allowed
+	})
+}
+
+// WithEventBridge sets whether the AWS EventBridge "cron(...)" and "rate(...)"
+// expressions are accepted (the default), see ParseEventBridge.
+func WithEventBridge(allowed bool) ParserOpt {
+	return ParserOpt(func(parser *Parser) {
+		parser.eventBridge = allowed
+	})
+}
+
+// WithStandardLayout makes the parser accept the 5 fields of the standard Unix
+// crontab, starting with the minute: "minute hour dom month dow". The fields
+// can still be changed by the options following this one, e.g.
+// WithSecondsField(FieldOptional) to accept a leading seconds field.
+func WithStandardLayout() ParserOpt {
+	return ParserOpt(func(parser *Parser) {
+		parser.seconds = FieldUnused
+		parser.dow = FieldRequired
+		parser.year = FieldUnused
+	})
+}
+
+// WithHorizon sets the number of years the parsed schedules look ahead for an
+// activation time, see SpecSchedule.Horizon.
+func WithHorizon(years int) ParserOpt {
+	return ParserOpt(func(parser *Parser) {
+		parser.horizon = years
+	})
+}
+
+// WithDSTPolicy sets how the parsed schedules handle the daylight saving time
+// transitions, see DSTPolicy.
+func WithDSTPolicy(policy DSTPolicy) ParserOpt {
+	return ParserOpt(func(parser *Parser) {
+		parser.dst = policy
+	})
+}
+
+// WithMaxInterval makes the parser reject the schedules which can be
+// activated more than the given duration apart, with ErrTooRare. The intervals
+// of the cron expressions are checked over each year of their calendar cycle,
+// and the ones of the other schedules over a year after their first activation
+// from 2001, so that the result doesn't depend on the time of parsing.
+func WithMaxInterval(max time.Duration) ParserOpt {
+	return ParserOpt(func(parser *Parser) {
+		parser.maxInterval = max
+	})
+}
+
+// NewParser returns a Parser configured with the given options. Without
+// options, it parses specs as Parse does.
+//
+// It returns an error if the layout is ambiguous, that is if optional seconds
+// are combined with another optional field: a spec with a missing field could
+// not tell which one is missing.
+func NewParser(opts ...ParserOpt) (*Parser, error) {
+	parser := &Parser{
+		seconds:     FieldRequired,
+		dow:         FieldOptional,
+		year:        FieldOptional,
+		descriptors: true,
+		eventBridge: true,
+	}
+	for _, opt := range opts {
+		opt(parser)
+	}
+	if parser.seconds == FieldOptional && (parser.dow == FieldOptional || parser.year == FieldOptional) {
+		return nil, errors.New("ambiguous parser layout: optional seconds cannot be combined with other optional fields")
+	}
+	return parser, nil
+}
+
+// defaultParser is the parser used by Parse and by Cron by default.
+var defaultParser, _ = NewParser()
+
 // Parse returns a new crontab schedule representing the given spec.
-// It returns a descriptive error if the spec is not valid.
+// It returns a *ParseError if the spec is not valid.
 //
 // It accepts
 //   - Full crontab specs, e.g. "* * * * * ?"
-//   - Descriptors, e.g. "@midnight", "@every 1h30m"
-func Parse(spec string) (_ Schedule, err error) {
-	// Convert panics into errors
-	defer func() {
-		if recovered := recover(); recovered != nil {
-			err = fmt.Errorf("%v", recovered)
+//   - Descriptors, e.g. "@midnight", "@every 1h30m", "@at 2026-11-01T03:00:00Z"
+//     "@random 02:00-04:00 daily" or "@sunset 48.8566 2.3522 30m"
+//   - ISO 8601 repeating intervals, e.g. "R5/2026-11-01T03:00:00Z/PT90M", see
+//     ParseInterval
+//   - RFC 5545 recurrence rules, e.g. "RRULE:FREQ=WEEKLY;BYDAY=MO,TH", see
+//     ParseRRule
+//   - systemd calendar events prefixed by "OnCalendar=", e.g.
+//     "OnCalendar=Mon..Fri *-*-* 09:00:00", see ParseCalendarEvent
+//   - AWS EventBridge expressions, e.g. "cron(0 12 ? * MON-FRI *)" or
+//     "rate(5 minutes)", see ParseEventBridge
+//   - Any of the above prefixed by a time zone, e.g.
+//     "CRON_TZ=Europe/Paris 0 0 6 * * *" or "TZ=UTC @daily"
+//
+// The "H" tokens and the "@random" descriptor of the spec are resolved with an
+// empty key, see ParseHashed.
+//
+// Cron expressions and calendar events which can never be activated, such as
+// "0 0 0 30 2 *" (February 30th), are rejected with ErrUnsatisfiable.
+//
+// Parse uses the default layout: "second minute hour dom month [dow [year]]".
+// Use a Parser for other layouts.
+func Parse(spec string) (Schedule, error) {
+	return defaultParser.Parse(spec)
+}
+
+// ParseHashed is like Parse, but resolves the "H" tokens of the spec from the
+// given key: "H" stands for a value of the field derived from a hash of the key,
+// "H(0-29)" for such a value within the range, and "H/15" for every 15th value
+// starting from such an offset. The time of "@random" descriptors within their
+// window is derived from the key as well. The same key always gives the same
+// schedule, but different keys spread their activation times over the allowed
+// values.
+func ParseHashed(spec, key string) (Schedule, error) {
+	return defaultParser.ParseHashed(spec, key)
+}
+
+// Parse returns a new crontab schedule representing the given spec, as the Parse
+// function does but with the layout of the parser.
+func (p *Parser) Parse(spec string) (Schedule, error) {
+	return p.ParseHashed(spec, "")
+}
+
+// ParseHashed is like Parse, but resolves the "H" tokens of the spec from the
+// given key, as the ParseHashed function does.
+func (p *Parser) ParseHashed(spec, key string) (Schedule, error) {
+	schedule, err := p.parseHashed(strings.TrimSpace(spec), key)
+	if err == nil {
+		err = p.check(schedule)
+	}
+	if err != nil {
+		err.Spec = spec
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (p *Parser) parseHashed(spec, key string) (Schedule, *ParseError) {
+	if spec == "" {
+		return nil, parseErrorf(ErrEmptySpec, "", "")
+	}
+
+	// Extract the time zone, if any.
+	var loc *time.Location
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		eq := strings.Index(spec, "=")
+		i := strings.IndexAny(spec, " \t")
+		if i == -1 {
+			return nil, parseErrorf(ErrEmptySpec, "", "nothing after the time zone")
+		}
+		if i == eq+1 {
+			return nil, parseErrorf(ErrUnknownLocation, "", "empty time zone name")
+		}
+		var err error
+		loc, err = time.LoadLocation(spec[eq+1 : i])
+		if err != nil {
+			return nil, parseErrorf(ErrUnknownLocation, spec[eq+1:i], "%v", err)
 		}
-	}()
+		spec = strings.TrimSpace(spec[i:])
+	}
 
-	if spec[0] == '@' {
-		return parseDescriptor(spec), nil
+	if strings.HasPrefix(spec, "RRULE:") || strings.HasPrefix(spec, "DTSTART") {
+		schedule, err := parseRRule(spec, loc)
+		if err != nil {
+			return nil, err
+		}
+		schedule.Horizon = p.horizon
+		return schedule, nil
 	}
 
-	// Split on whitespace.  We require 5 or 6 fields.
-	// (second) (minute) (hour) (day of month) (month) (day of week, optional)
-	fields := strings.Fields(spec)
-	if len(fields) != 5 && len(fields) != 6 {
-		log.Panicf("Expected 5 or 6 fields, found %d: %s", len(fields), spec)
+	if strings.HasPrefix(spec, "cron(") || strings.HasPrefix(spec, "rate(") {
+		if !p.eventBridge {
+			return nil, parseErrorf(ErrInvalidValue, spec, "EventBridge expressions are not allowed")
+		}
+		schedule, err := parseEventBridge(spec)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := schedule.(*SpecSchedule)
+		if !ok {
+			if loc != nil {
+				return nil, zoneError(loc, "rate expressions")
+			}
+			return schedule, nil
+		}
+		if loc != nil {
+			s.Location = loc
+		}
+		s.Horizon = p.horizon
+		s.DST = p.dst
+		return schedule, nil
 	}
 
-	// If a sixth field is not provided (DayOfWeek), then it is equivalent to star.
-	if len(fields) == 5 {
-		fields = append(fields, "*")
+	if strings.HasPrefix(spec, "R") && strings.Contains(spec, "/") {
+		if loc != nil {
+			return nil, zoneError(loc, "repeating intervals")
+		}
+		return parseInterval(spec)
 	}
 
-	schedule := &SpecSchedule{
-		Second: getField(fields[0], seconds),
-		Minute: getField(fields[1], minutes),
-		Hour:   getField(fields[2], hours),
-		Dom:    getField(fields[3], dom),
-		Month:  getField(fields[4], months),
-		Dow:    getField(fields[5], dow),
+	if strings.HasPrefix(spec, "OnCalendar=") {
+		schedule, err := parseCalendarEvent(spec[len("OnCalendar="):])
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case schedule.Location == nil:
+			schedule.Location = loc
+		case loc != nil && loc.String() != schedule.Location.String():
+			return nil, parseErrorf(ErrInvalidValue, schedule.Location.String(), "the time zone differs from %s", loc)
+		}
+		schedule.Horizon = p.horizon
+		schedule.DST = p.dst
+		return schedule, nil
+	}
+
+	if spec[0] == '@' {
+		if !p.descriptors {
+			return nil, parseErrorf(ErrUnknownDescriptor, spec, "descriptors are not allowed")
+		}
+		schedule, err := parseDescriptor(spec, key)
+		if err != nil {
+			return nil, err
+		}
+		switch s := schedule.(type) {
+		case *SpecSchedule:
+			s.Location = loc
+			s.Horizon = p.horizon
+			s.DST = p.dst
+		case *RandomSchedule:
+			s.Location = loc
+		default:
+			if loc != nil {
+				return nil, zoneError(loc, "the "+strings.Fields(spec)[0]+" descriptor")
+			}
+		}
+		return schedule, nil
+	}
+
+	// Split on whitespace, and place the fields in the full layout:
+	// (second) (minute) (hour) (day of month) (month) (day of week) (year)
+	fields, positions, err := p.layout(strings.Fields(spec))
+	if err != nil {
+		err.Token = spec
+		return nil, err
+	}
+
+	schedule := &SpecSchedule{Location: loc, Horizon: p.horizon, DST: p.dst}
+	for i, field := range fields {
+		hash := fieldHash(key, i)
+		switch i {
+		case 0:
+			schedule.Second, err = getField(field, seconds, hash)
+		case 1:
+			schedule.Minute, err = getField(field, minutes, hash)
+		case 2:
+			schedule.Hour, err = getField(field, hours, hash)
+		case 3:
+			schedule.Dom, schedule.DomLast, schedule.DomWeekday, err = getDomField(field, hash)
+		case 4:
+			schedule.Month, err = getField(field, months, hash)
+		case 5:
+			schedule.Dow, schedule.DowLast, schedule.DowNth, err = getDowField(field, hash)
+		case 6:
+			schedule.Years, err = getYearField(field)
+		}
+		if err != nil {
+			err.Field = fieldNames[i]
+			err.Index = positions[i]
+			return nil, err
+		}
 	}
 
 	return schedule, nil
 }
 
+// layout places the fields of a spec in the full 7-field layout, filling the
+// missing ones with their default value. It also returns the position of each
+// field in the spec, -1 for the missing ones.
+func (p *Parser) layout(fields []string) ([]string, []int, *ParseError) {
+	modes := []FieldMode{p.seconds, FieldRequired, FieldRequired, FieldRequired, FieldRequired, p.dow, p.year}
+	defaults := []string{"0", "", "", "", "", "*", "*"}
+
+	var required, optional int
+	for _, mode := range modes {
+		switch mode {
+		case FieldRequired:
+			required++
+		case FieldOptional:
+			optional++
+		}
+	}
+	if len(fields) < required || len(fields) > required+optional {
+		if optional == 0 {
+			return nil, nil, parseErrorf(ErrFieldCount, "", "expected %d, found %d", required, len(fields))
+		}
+		return nil, nil, parseErrorf(ErrFieldCount, "", "expected %d to %d, found %d", required, required+optional, len(fields))
+	}
+
+	// The optional fields are filled from left to right.
+	optional = len(fields) - required
+	result := make([]string, len(modes))
+	positions := make([]int, len(modes))
+	next := 0
+	for i, mode := range modes {
+		if mode == FieldRequired || mode == FieldOptional && optional > 0 {
+			if mode == FieldOptional {
+				optional--
+			}
+			result[i] = fields[next]
+			positions[i] = next
+			next++
+			continue
+		}
+		result[i] = defaults[i]
+		positions[i] = -1
+	}
+	return result, positions, nil
+}
+
 // getField returns an Int with the bits set representing all of the times that
 // the field represents.  A "field" is a comma-separated list of "ranges".
-func getField(field string, r bounds) uint64 {
+func getField(field string, r bounds, hash uint64) (uint64, *ParseError) {
 	// list = range {"," range}
 	var bits uint64
-	ranges := strings.FieldsFunc(field, func(r rune) bool { return r == ',' })
+	ranges, err := splitList(field)
+	if err != nil {
+		return 0, err
+	}
 	for _, expr := range ranges {
-		bits |= getRange(expr, r)
+		b, err := getRange(expr, r, hash)
+		if err != nil {
+			err.Token = expr
+			return 0, err
+		}
+		bits |= b
 	}
-	return bits
+	return bits, nil
+}
+
+// splitList returns the comma-separated items of the given field, or an error
+// if one of them is empty.
+func splitList(field string) ([]string, *ParseError) {
+	items := strings.Split(field, ",")
+	for _, item := range items {
+		if item == "" {
+			return nil, parseErrorf(ErrInvalidValue, field, "empty item in the list")
+		}
+	}
+	return items, nil
+}
+
+// getDomField parses a day-of-month field. Along with the bits of the days, it
+// returns the bits of the "L" and "W" modifiers (see SpecSchedule):
+//
+//	L | L-number | LW | number W
+func getDomField(field string, hash uint64) (bits, last, weekday uint64, err *ParseError) {
+	ranges, err := splitList(field)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	for _, expr := range ranges {
+		var (
+			b     uint64
+			n     uint
+			upper = strings.ToUpper(expr)
+		)
+		switch {
+		case upper == "L":
+			last |= 1
+		case upper == "LW":
+			weekday |= 1
+		case strings.HasPrefix(upper, "L-"):
+			n, err = parseInt(expr[2:])
+			if err == nil && n >= dom.max {
+				err = parseErrorf(ErrOutOfRange, expr, "offset from the last day of the month above maximum (%d)", dom.max-1)
+			}
+			last |= 1 << n
+		case strings.HasSuffix(upper, "W"):
+			n, err = parseInt(expr[:len(expr)-1])
+			if err == nil && (n < dom.min || n > dom.max) {
+				err = parseErrorf(ErrOutOfRange, expr, "day of month not in %d-%d", dom.min, dom.max)
+			}
+			weekday |= 1 << n
+		case expr == "H" || strings.HasPrefix(expr, "H/"):
+			// Only pick days that exist in every month.
+			b, err = getRange("H(1-28)"+expr[1:], dom, hash)
+			bits |= b
+		default:
+			b, err = getRange(expr, dom, hash)
+			bits |= b
+		}
+		if err != nil {
+			err.Token = expr
+			return 0, 0, 0, err
+		}
+	}
+	return bits, last, weekday, nil
+}
+
+// getDowField parses a day-of-week field. Along with the bits of the days, it
+// returns the bits of the "L" and "#" modifiers (see SpecSchedule):
+//
+//	day L | day "#" number
+func getDowField(field string, hash uint64) (bits, last, nth uint64, err *ParseError) {
+	ranges, err := splitList(field)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	for _, expr := range ranges {
+		var (
+			b    uint64
+			d, n uint
+		)
+		switch {
+		case len(expr) > 1 && strings.HasSuffix(strings.ToUpper(expr), "L"):
+			d, err = parseIntOrName(expr[:len(expr)-1], dow.names)
+			if err == nil && d > dow.max {
+				err = parseErrorf(ErrOutOfRange, expr, "day of week above maximum (%d)", dow.max)
+			}
+			last |= 1 << d
+		case strings.Contains(expr, "#"):
+			dayAndNth := strings.Split(expr, "#")
+			if len(dayAndNth) != 2 {
+				err = parseErrorf(ErrInvalidValue, expr, "too many hashes")
+				break
+			}
+			d, err = parseIntOrName(dayAndNth[0], dow.names)
+			if err == nil && d > dow.max {
+				err = parseErrorf(ErrOutOfRange, expr, "day of week above maximum (%d)", dow.max)
+			}
+			if err == nil {
+				n, err = parseInt(dayAndNth[1])
+			}
+			if err == nil && (n < 1 || n > 5) {
+				err = parseErrorf(ErrOutOfRange, expr, "occurrence of day of week not in 1-5")
+			}
+			nth |= 1 << ((n-1)*7 + d)
+		default:
+			b, err = getRange(expr, dow, hash)
+			bits |= b
+		}
+		if err != nil {
+			err.Token = expr
+			return 0, 0, 0, err
+		}
+	}
+	return bits, last, nth, nil
 }
 
 // getRange returns the bits indicated by the given expression:
-//   number | number "-" number [ "/" number ]
-func getRange(expr string, r bounds) uint64 {
+//
+//	number | number "-" number [ "/" number ]
+//	"H" [ "(" number "-" number ")" ] [ "/" number ]
+//
+// The hash is used to resolve the value of "H".
+func getRange(expr string, r bounds, hash uint64) (uint64, *ParseError) {
+	start, end, step, star, err := parseRange(expr, r, hash)
+	if err != nil {
+		return 0, err
+	}
+
+	var extra_star uint64
+	if star {
+		extra_star = starBit
+	}
+	return r.bits(start, end, step) | extra_star, nil
+}
+
+// getYearField returns the years represented by the given field, in ascending
+// order, or nil if it includes every year. A field is a comma-separated list of
+// ranges, as for the other fields.
+func getYearField(field string) ([]int, *ParseError) {
+	set := map[int]bool{}
+	ranges, err := splitList(field)
+	if err != nil {
+		return nil, err
+	}
+	for _, expr := range ranges {
+		if strings.HasPrefix(expr, "H") {
+			return nil, parseErrorf(ErrInvalidValue, expr, "hashed values are not allowed for years")
+		}
+		start, end, step, star, err := parseRange(expr, years, 0)
+		if err == nil && start > end {
+			err = parseErrorf(ErrBadRange, expr, "beginning of range (%d) beyond end of range (%d)", start, end)
+		}
+		if err != nil {
+			err.Token = expr
+			return nil, err
+		}
+		if star && step == 1 {
+			return nil, nil
+		}
+		for year := start; year <= end; year += step {
+			set[int(year)] = true
+		}
+	}
+
+	var result []int
+	for year := range set {
+		result = append(result, year)
+	}
+	sort.Ints(result)
+	return result, nil
+}
+
+// parseRange returns the boundaries and the step of the given range
+// expression, and whether it is a star. "H" values are resolved from the hash.
+func parseRange(expr string, r bounds, hash uint64) (start, end, step uint, star bool, err *ParseError) {
 	var (
-		start, end, step uint
-		rangeAndStep     = strings.Split(expr, "/")
-		lowAndHigh       = strings.Split(rangeAndStep[0], "-")
-		singleDigit      = len(lowAndHigh) == 1
+		rangeAndStep = strings.Split(expr, "/")
+		lowAndHigh   = strings.Split(rangeAndStep[0], "-")
+		singleDigit  = len(lowAndHigh) == 1
+		hashed       = strings.HasPrefix(rangeAndStep[0], "H")
 	)
 
-	var extra_star uint64
-	if lowAndHigh[0] == "*" || lowAndHigh[0] == "?" {
+	if hashed {
+		start, end = r.min, r.max
+		if hashRange := rangeAndStep[0][1:]; hashRange != "" {
+			if !strings.HasPrefix(hashRange, "(") || !strings.HasSuffix(hashRange, ")") {
+				return 0, 0, 0, false, parseErrorf(ErrBadRange, expr, "expected H(number-number)")
+			}
+			lowAndHigh = strings.Split(hashRange[1:len(hashRange)-1], "-")
+			if len(lowAndHigh) != 2 {
+				return 0, 0, 0, false, parseErrorf(ErrBadRange, expr, "expected H(number-number)")
+			}
+			if start, err = parseIntOrName(lowAndHigh[0], r.names); err != nil {
+				return 0, 0, 0, false, err
+			}
+			if end, err = parseIntOrName(lowAndHigh[1], r.names); err != nil {
+				return 0, 0, 0, false, err
+			}
+		}
+	} else if lowAndHigh[0] == "*" || lowAndHigh[0] == "?" {
 		start = r.min
 		end = r.max
-		extra_star = starBit
+		star = true
 	} else {
-		start = parseIntOrName(lowAndHigh[0], r.names)
+		if start, err = parseIntOrName(lowAndHigh[0], r.names); err != nil {
+			return 0, 0, 0, false, err
+		}
 		switch len(lowAndHigh) {
 		case 1:
 			end = start
 		case 2:
-			end = parseIntOrName(lowAndHigh[1], r.names)
+			if end, err = parseIntOrName(lowAndHigh[1], r.names); err != nil {
+				return 0, 0, 0, false, err
+			}
 		default:
-			log.Panicf("Too many hyphens: %s", expr)
+			return 0, 0, 0, false, parseErrorf(ErrBadRange, expr, "too many hyphens")
 		}
 	}
 
@@ -94,50 +695,73 @@ func getRange(expr string, r bounds) uint64 {
 	case 1:
 		step = 1
 	case 2:
-		step = mustParseInt(rangeAndStep[1])
+		var perr *ParseError
+		step, perr = parseInt(rangeAndStep[1])
+		if perr != nil || step == 0 {
+			return 0, 0, 0, false, parseErrorf(ErrBadStep, expr, "step should be a positive number")
+		}
 
 		// Special handling: "N/step" means "N-max/step".
-		if singleDigit {
+		if singleDigit && !hashed {
 			end = r.max
 		}
 	default:
-		log.Panicf("Too many slashes: %s", expr)
+		return 0, 0, 0, false, parseErrorf(ErrBadStep, expr, "too many slashes")
 	}
 
 	if start < r.min {
-		log.Panicf("Beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
+		return 0, 0, 0, false, parseErrorf(ErrOutOfRange, expr, "beginning of range (%d) below minimum (%d)", start, r.min)
 	}
 	if end > r.max {
-		log.Panicf("End of range (%d) above maximum (%d): %s", end, r.max, expr)
+		return 0, 0, 0, false, parseErrorf(ErrOutOfRange, expr, "end of range (%d) above maximum (%d)", end, r.max)
 	}
-	if start > end {
-		log.Panicf("Beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
+
+	if hashed {
+		// "H" picks a single value of the range, "H/step" the offset of the
+		// first value.
+		span := r.span(start, end)
+		if step > 1 && step < span {
+			span = step
+		}
+		start = r.add(start, uint(hash%uint64(span)))
+		if step == 1 {
+			end = start
+		}
 	}
 
-	return getBits(start, end, step) | extra_star
+	return start, end, step, star, nil
+}
+
+// fieldHash returns the hash resolving the "H" values of the given field from
+// the key.
+func fieldHash(key string, field int) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{byte(field)})
+	return h.Sum64()
 }
 
 // parseIntOrName returns the (possibly-named) integer contained in expr.
-func parseIntOrName(expr string, names map[string]uint) uint {
+func parseIntOrName(expr string, names map[string]uint) (uint, *ParseError) {
 	if names != nil {
 		if namedInt, ok := names[strings.ToLower(expr)]; ok {
-			return namedInt
+			return namedInt, nil
 		}
 	}
-	return mustParseInt(expr)
+	return parseInt(expr)
 }
 
-// mustParseInt parses the given expression as an int or panics.
-func mustParseInt(expr string) uint {
+// parseInt parses the given expression as a non-negative int.
+func parseInt(expr string) (uint, *ParseError) {
 	num, err := strconv.Atoi(expr)
 	if err != nil {
-		log.Panicf("Failed to parse int from %s: %s", expr, err)
+		return 0, parseErrorf(ErrInvalidValue, expr, "not a number")
 	}
 	if num < 0 {
-		log.Panicf("Negative number (%d) not allowed: %s", num, expr)
+		return 0, parseErrorf(ErrOutOfRange, expr, "negative numbers are not allowed")
 	}
 
-	return uint(num)
+	return uint(num), nil
 }
 
 // getBits sets all bits in the range [min, max], modulo the given step size.
@@ -156,14 +780,47 @@ func getBits(min, max, step uint) uint64 {
 	return bits
 }
 
+// span returns the number of values from start to end. If start is beyond end,
+// the range wraps around, e.g. 22-2 for the hours is 22, 23, 0, 1 and 2.
+func (r bounds) span(start, end uint) uint {
+	if start > end {
+		return r.max - start + 1 + end - r.min + 1
+	}
+	return end - start + 1
+}
+
+// add returns the value n after the given one, wrapping around the bounds.
+func (r bounds) add(value, n uint) uint {
+	return r.min + (value-r.min+n)%(r.max-r.min+1)
+}
+
+// bits returns the bits of the range from start to end, possibly wrapping
+// around, with the given step.
+func (r bounds) bits(start, end, step uint) uint64 {
+	if start <= end {
+		return getBits(start, end, step)
+	}
+	var bits uint64
+	for n := uint(0); n < r.span(start, end); n += step {
+		bits |= 1 << r.add(start, n)
+	}
+	return bits
+}
+
 // all returns all bits within the given bounds.  (plus the star bit)
 func all(r bounds) uint64 {
 	return getBits(r.min, r.max, 1) | starBit
 }
 
-// parseDescriptor returns a pre-defined schedule for the expression, or panics
-// if none matches.
-func parseDescriptor(spec string) Schedule {
+// zoneError returns the error of a time zone given for schedules whose
+// activation times are absolute, and don't depend on it.
+func zoneError(loc *time.Location, schedules string) *ParseError {
+	return parseErrorf(ErrInvalidValue, loc.String(), "a time zone cannot be given for %s", schedules)
+}
+
+// parseDescriptor returns a pre-defined schedule for the expression, or an
+// error if none matches. The "@random" descriptor is keyed by the given key.
+func parseDescriptor(spec, key string) (Schedule, *ParseError) {
 	switch spec {
 	case "@yearly", "@annually":
 		return &SpecSchedule{
@@ -173,7 +830,7 @@ func parseDescriptor(spec string) Schedule {
 			Dom:    1 << dom.min,
 			Month:  1 << months.min,
 			Dow:    all(dow),
-		}
+		}, nil
 
 	case "@monthly":
 		return &SpecSchedule{
@@ -183,7 +840,7 @@ func parseDescriptor(spec string) Schedule {
 			Dom:    1 << dom.min,
 			Month:  all(months),
 			Dow:    all(dow),
-		}
+		}, nil
 
 	case "@weekly":
 		return &SpecSchedule{
@@ -193,7 +850,7 @@ func parseDescriptor(spec string) Schedule {
 			Dom:    all(dom),
 			Month:  all(months),
 			Dow:    1 << dow.min,
-		}
+		}, nil
 
 	case "@daily", "@midnight":
 		return &SpecSchedule{
@@ -203,7 +860,7 @@ func parseDescriptor(spec string) Schedule {
 			Dom:    all(dom),
 			Month:  all(months),
 			Dow:    all(dow),
-		}
+		}, nil
 
 	case "@hourly":
 		return &SpecSchedule{
@@ -213,18 +870,63 @@ func parseDescriptor(spec string) Schedule {
 			Dom:    all(dom),
 			Month:  all(months),
 			Dow:    all(dow),
-		}
+		}, nil
 	}
 
 	const every = "@every "
 	if strings.HasPrefix(spec, every) {
-		duration, err := time.ParseDuration(spec[len(every):])
+		return parseEvery(spec[len(every):])
+	}
+
+	const random = "@random "
+	if strings.HasPrefix(spec, random) {
+		return parseRandom(spec[len(random):], key)
+	}
+
+	for _, event := range []SolarEvent{Sunrise, Sunset} {
+		if prefix := "@" + event.String() + " "; strings.HasPrefix(spec, prefix) {
+			return parseSolar(event, spec[len(prefix):])
+		}
+	}
+
+	const at = "@at "
+	if strings.HasPrefix(spec, at) {
+		t, err := time.Parse(time.RFC3339, spec[len(at):])
 		if err != nil {
-			log.Panicf("Failed to parse duration %s: %s", spec, err)
+			return nil, parseErrorf(ErrInvalidValue, spec[len(at):], "expected an RFC 3339 time")
 		}
-		return Every(duration)
+		return Once(t), nil
 	}
 
-	log.Panicf("Unrecognized descriptor: %s", spec)
-	return nil
+	return nil, parseErrorf(ErrUnknownDescriptor, spec, "")
+}
+
+// parseEvery parses the arguments of an "@every" descriptor: a duration,
+// optionally followed by "offset <duration>" or "from <RFC 3339 time>". The
+// schedule is anchored to the Unix epoch, moved by the offset, or to the given
+// time.
+func parseEvery(args string) (Schedule, *ParseError) {
+	anchor := unixEpoch
+	if value, offset, ok := strings.Cut(args, " offset "); ok {
+		d, err := time.ParseDuration(offset)
+		if err != nil {
+			return nil, parseErrorf(ErrBadDuration, offset, "%v", err)
+		}
+		args, anchor = value, unixEpoch.Add(d)
+	} else if value, from, ok := strings.Cut(args, " from "); ok {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, parseErrorf(ErrInvalidValue, from, "expected an RFC 3339 time")
+		}
+		args, anchor = value, t
+	}
+
+	duration, err := time.ParseDuration(args)
+	if err != nil {
+		return nil, parseErrorf(ErrBadDuration, args, "%v", err)
+	}
+	if duration <= 0 {
+		return nil, parseErrorf(ErrBadDuration, args, "duration should be positive")
+	}
+	return EveryFrom(duration, anchor), nil
 }
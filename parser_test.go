@@ -1,6 +1,12 @@
 package etcdcron
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"math/bits"
+	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -26,10 +32,19 @@ func TestRange(t *testing.T) {
 
 		{"*", 1, 3, 1<<1 | 1<<2 | 1<<3 | starBit},
 		{"*/2", 1, 3, 1<<1 | 1<<3 | starBit},
+
+		// Wrap-around ranges
+		{"6-1", 0, 7, 1<<6 | 1<<7 | 1<<0 | 1<<1},
+		{"6-2/2", 0, 7, 1<<6 | 1<<0 | 1<<2},
+		{"5-4/3", 0, 7, 1<<5 | 1<<0 | 1<<3},
+		{"3-1", 1, 3, 1<<3 | 1<<1},
 	}
 
 	for _, c := range ranges {
-		actual := getRange(c.expr, bounds{c.min, c.max, nil})
+		actual, err := getRange(c.expr, bounds{c.min, c.max, nil}, 0)
+		if err != nil {
+			t.Error(err)
+		}
 		if actual != c.expected {
 			t.Errorf("%s => (expected) %d != %d (actual)", c.expr, c.expected, actual)
 		}
@@ -49,7 +64,10 @@ func TestField(t *testing.T) {
 	}
 
 	for _, c := range fields {
-		actual := getField(c.expr, bounds{c.min, c.max, nil})
+		actual, err := getField(c.expr, bounds{c.min, c.max, nil}, 0)
+		if err != nil {
+			t.Error(err)
+		}
 		if actual != c.expected {
 			t.Errorf("%s => (expected) %d != %d (actual)", c.expr, c.expected, actual)
 		}
@@ -101,8 +119,11 @@ func TestSpecSchedule(t *testing.T) {
 		expr     string
 		expected Schedule
 	}{
-		{"* 5 * * * *", &SpecSchedule{all(seconds), 1 << 5, all(hours), all(dom), all(months), all(dow)}},
-		{"@every 5m", ConstantDelaySchedule{time.Duration(5) * time.Minute}},
+		{"* 5 * * * *", &SpecSchedule{Second: all(seconds), Minute: 1 << 5, Hour: all(hours), Dom: all(dom), Month: all(months), Dow: all(dow)}},
+		{"@every 5m", ConstantDelaySchedule{Delay: time.Duration(5) * time.Minute, Anchor: time.Unix(0, 0).UTC()}},
+		{"@every 1h offset 15m", ConstantDelaySchedule{Delay: time.Hour, Anchor: time.Unix(15*60, 0).UTC()}},
+		{"CRON_TZ=UTC * 5 * * * *", &SpecSchedule{Second: all(seconds), Minute: 1 << 5, Hour: all(hours), Dom: all(dom), Month: all(months), Dow: all(dow), Location: time.UTC}},
+		{"TZ=UTC @hourly", &SpecSchedule{Second: 1, Minute: 1, Hour: all(hours), Dom: all(dom), Month: all(months), Dow: all(dow), Location: time.UTC}},
 	}
 
 	for _, c := range entries {
@@ -115,3 +136,382 @@ func TestSpecSchedule(t *testing.T) {
 		}
 	}
 }
+
+func TestParseLocation(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	specs := []struct {
+		spec     string
+		expected *time.Location
+	}{
+		{"0 0 6 * * *", nil},
+		{"CRON_TZ=Europe/Paris 0 0 6 * * *", paris},
+		{"TZ=Europe/Paris 0 0 6 * * *", paris},
+		{"CRON_TZ=Europe/Paris @daily", paris},
+	}
+
+	for _, c := range specs {
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.(*SpecSchedule).Location
+		if actual.String() != c.expected.String() {
+			t.Errorf("%s => (expected) %v != %v (actual)", c.spec, c.expected, actual)
+		}
+	}
+
+	invalidSpecs := []string{
+		"CRON_TZ=Europe/Nowhere 0 0 6 * * *",
+		"TZ=Europe/Paris",
+	}
+	for _, spec := range invalidSpecs {
+		_, err := Parse(spec)
+		if err == nil {
+			t.Error("expected an error parsing: ", spec)
+		}
+	}
+}
+
+func TestDayModifiers(t *testing.T) {
+	fields := []struct {
+		expr                     string
+		dom, domLast, domWeekday uint64
+		dow, dowLast, dowNth     uint64
+	}{
+		{"0 0 0 L * ?", 0, 1, 0, all(dow), 0, 0},
+		{"0 0 0 L-3 * ?", 0, 1 << 3, 0, all(dow), 0, 0},
+		{"0 0 0 LW * ?", 0, 0, 1, all(dow), 0, 0},
+		{"0 0 0 15W * ?", 0, 0, 1 << 15, all(dow), 0, 0},
+		{"0 0 0 1,15W,L * ?", 1 << 1, 1, 1 << 15, all(dow), 0, 0},
+		{"0 0 0 ? * 5L", all(dom), 0, 0, 0, 1 << 5, 0},
+		{"0 0 0 ? * friL", all(dom), 0, 0, 0, 1 << 5, 0},
+		{"0 0 0 ? * 2#3", all(dom), 0, 0, 0, 0, 1 << (2*7 + 2)},
+		{"0 0 0 ? * MON#1,MON", all(dom), 0, 0, 1 << 1, 0, 1 << 1},
+	}
+
+	for _, c := range fields {
+		sched, err := Parse(c.expr)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.(*SpecSchedule)
+		if actual.Dom != c.dom || actual.DomLast != c.domLast || actual.DomWeekday != c.domWeekday {
+			t.Errorf("%s => (expected) %b %b %b != %b %b %b (actual)", c.expr,
+				c.dom, c.domLast, c.domWeekday, actual.Dom, actual.DomLast, actual.DomWeekday)
+		}
+		if actual.Dow != c.dow || actual.DowLast != c.dowLast || actual.DowNth != c.dowNth {
+			t.Errorf("%s => (expected) %b %b %b != %b %b %b (actual)", c.expr,
+				c.dow, c.dowLast, c.dowNth, actual.Dow, actual.DowLast, actual.DowNth)
+		}
+	}
+}
+
+func TestYearField(t *testing.T) {
+	fields := []struct {
+		expr     string
+		expected []int
+	}{
+		{"*", nil},
+		{"?", nil},
+		{"2027", []int{2027}},
+		{"2027-2030", []int{2027, 2028, 2029, 2030}},
+		{"2030,2027-2028", []int{2027, 2028, 2030}},
+		{"2027-2031/2", []int{2027, 2029, 2031}},
+		{"2095/2", []int{2095, 2097, 2099}},
+		{"2027,*", nil},
+	}
+
+	for _, c := range fields {
+		actual, err := getYearField(c.expr)
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s => (expected) %v != %v (actual)", c.expr, c.expected, actual)
+		}
+	}
+}
+
+func TestHashedRange(t *testing.T) {
+	ranges := []struct {
+		expr       string
+		min, max   uint
+		step       uint
+		population int
+	}{
+		{"H", 0, 59, 1, 1},
+		{"H(0-29)", 0, 29, 1, 1},
+		{"H(10-12)", 10, 12, 1, 1},
+		{"H/15", 0, 59, 15, 4},
+		{"H(0-29)/10", 0, 29, 10, 3},
+	}
+
+	for _, c := range ranges {
+		values := map[uint64]bool{}
+		for i := 0; i < 100; i++ {
+			hash := fieldHash(fmt.Sprintf("job-%d", i), 1)
+			actual, err := getRange(c.expr, minutes, hash)
+			if err != nil {
+				t.Error(err)
+			}
+			if again, _ := getRange(c.expr, minutes, hash); actual != again {
+				t.Errorf("%s => not deterministic", c.expr)
+			}
+			if bits.OnesCount64(actual) != c.population {
+				t.Errorf("%s => (expected) %d values != %b (actual)", c.expr, c.population, actual)
+			}
+			first := uint(bits.TrailingZeros64(actual))
+			if first < c.min || first > c.max || first >= c.min+c.step && c.step > 1 {
+				t.Errorf("%s => (expected) first value in %d-%d != %b (actual)", c.expr, c.min, c.max, actual)
+			}
+			if c.step > 1 && actual != getBits(first, c.max, c.step) {
+				t.Errorf("%s => (expected) %b != %b (actual)", c.expr, getBits(first, c.max, c.step), actual)
+			}
+			values[actual] = true
+		}
+		if len(values) == 1 && c.min != c.max {
+			t.Errorf("%s => values are not spread: %v", c.expr, values)
+		}
+	}
+
+	// Wrap-around ranges.
+	wraps := []struct {
+		expr       string
+		r          bounds
+		field      int
+		start, end uint
+	}{
+		{"H(50-9)", minutes, 1, 50, 9},
+		{"H(29-0)", seconds, 0, 29, 0},
+	}
+	for _, c := range wraps {
+		values := map[uint64]bool{}
+		for i := 0; i < 100; i++ {
+			actual, err := getRange(c.expr, c.r, fieldHash(fmt.Sprintf("job-%d", i), c.field))
+			if err != nil {
+				t.Error(err)
+			}
+			if value := uint(bits.TrailingZeros64(actual)); bits.OnesCount64(actual) != 1 || value > c.end && value < c.start {
+				t.Errorf("%s => (expected) a value in %d-%d or %d-%d != %b (actual)", c.expr, c.start, c.r.max, c.r.min, c.end, actual)
+			}
+			values[actual] = true
+		}
+		if len(values) == 1 {
+			t.Errorf("%s => values are not spread: %v", c.expr, values)
+		}
+	}
+}
+
+func TestParseHashed(t *testing.T) {
+	a, err := ParseHashed("H H H H H H", "job-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseHashed("H H H H H H", "job-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, again) {
+		t.Errorf("(expected) %v != %v (actual)", a, again)
+	}
+
+	spec := a.(*SpecSchedule)
+	if spec.Dom < 1<<1 || spec.Dom > 1<<28 {
+		t.Errorf("hashed day of month should exist in every month: %b", spec.Dom)
+	}
+	for _, field := range []uint64{spec.Second, spec.Minute, spec.Hour, spec.Dom, spec.Month, spec.Dow} {
+		if bits.OnesCount64(field) != 1 {
+			t.Errorf("hashed field should have a single value: %b", field)
+		}
+	}
+
+	invalidSpecs := []string{
+		"H(0-29 * * * * *",
+		"H(0-60) * * * * *",
+		"H(0) * * * * *",
+		"0 0 0 1 1 * H",
+	}
+	for _, spec := range invalidSpecs {
+		_, err := ParseHashed(spec, "job-a")
+		if err == nil {
+			t.Error("expected an error parsing: ", spec)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	// Invalid specs should not print anything on the standard logger.
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	errs := []struct {
+		spec  string
+		field string
+		index int
+		token string
+		kind  error
+	}{
+		{"", "", -1, "", ErrEmptySpec},
+		{"   ", "", -1, "", ErrEmptySpec},
+		{"xyz", "", -1, "xyz", ErrFieldCount},
+		{"* * * * * * * *", "", -1, "* * * * * * * *", ErrFieldCount},
+		{"60 0 * * *", "second", 0, "60", ErrOutOfRange},
+		{"0 60 * * *", "minute", 1, "60", ErrOutOfRange},
+		{"0 0 -1 * *", "hour", 2, "-1", ErrInvalidValue},
+		{"0 0 0 0 *", "day of month", 3, "0", ErrOutOfRange},
+		{"0 0 0 32W *", "day of month", 3, "32W", ErrOutOfRange},
+		{"0 0 0 L-X *", "day of month", 3, "L-X", ErrInvalidValue},
+		{"0 0 * * XYZ", "month", 4, "XYZ", ErrInvalidValue},
+		{"0 0 0 1 1 * 2030-2027", "year", 6, "2030-2027", ErrBadRange},
+		{"0 0 * * 1-2-3", "month", 4, "1-2-3", ErrBadRange},
+		{"0 0 * * * MON#6", "day of week", 5, "MON#6", ErrOutOfRange},
+		{"0 0 * * * MON#1#2", "day of week", 5, "MON#1#2", ErrInvalidValue},
+		{", * * * * *", "second", 0, ",", ErrInvalidValue},
+		{"0 0 0 1,,15 * *", "day of month", 3, "1,,15", ErrInvalidValue},
+		{"0 0 0 ? * MON,", "day of week", 5, "MON,", ErrInvalidValue},
+		{"0 0 0 1 1 * ,", "year", 6, ",", ErrInvalidValue},
+		{"*/0 * * * *", "second", 0, "*/0", ErrBadStep},
+		{"*/a * * * *", "second", 0, "*/a", ErrBadStep},
+		{"1/2/3 * * * *", "second", 0, "1/2/3", ErrBadStep},
+		{"0 0 0 1 1 * 2100", "year", 6, "2100", ErrOutOfRange},
+		{"H(0-29 * * * * *", "second", 0, "H(0-29", ErrBadRange},
+		{"@xyz", "", -1, "@xyz", ErrUnknownDescriptor},
+		{"@every 5 minutes", "", -1, "5 minutes", ErrBadDuration},
+		{"@every 1h offset 15", "", -1, "15", ErrBadDuration},
+		{"@every 0s", "", -1, "0s", ErrBadDuration},
+		{"@every -1s", "", -1, "-1s", ErrBadDuration},
+		{"@every 1h from tomorrow", "", -1, "tomorrow", ErrInvalidValue},
+		{"@random 02:00", "", -1, "02:00", ErrBadRange},
+		{"@random 02:00-25:00 daily", "", -1, "25:00", ErrInvalidValue},
+		{"@random 02:00-04:00 hourly", "", -1, "hourly", ErrInvalidValue},
+		{"@random 02:00-04:00 daily UTC", "", -1, "02:00-04:00 daily UTC", ErrInvalidValue},
+		{"@random 02:00-04:00 daily key backup", "", -1, "backup", ErrInvalidValue},
+		{"@sunset 48.8566", "", -1, "48.8566", ErrInvalidValue},
+		{"@sunset 48.8566 east", "", -1, "east", ErrInvalidValue},
+		{"@sunrise 91 0", "", -1, "91", ErrOutOfRange},
+		{"@sunrise 0 -180.5", "", -1, "-180.5", ErrOutOfRange},
+		{"@sunset 48.8566 2.3522 30", "", -1, "30", ErrBadDuration},
+		{"CRON_TZ=Europe/Nowhere 0 0 6 * * *", "", -1, "Europe/Nowhere", ErrUnknownLocation},
+		{"TZ=Europe/Paris", "", -1, "", ErrEmptySpec},
+		{"TZ= * * * * * *", "", -1, "", ErrUnknownLocation},
+		{"CRON_TZ= @daily", "", -1, "", ErrUnknownLocation},
+		{"CRON_TZ=Europe/Paris R5/2026-11-01T03:00:00Z/PT90M", "", -1, "Europe/Paris", ErrInvalidValue},
+		{"CRON_TZ=Europe/Paris @every 1h", "", -1, "Europe/Paris", ErrInvalidValue},
+		{"TZ=Europe/Paris @at 2026-11-01T03:00:00Z", "", -1, "Europe/Paris", ErrInvalidValue},
+		{"TZ=Europe/Paris @sunset 48.8566 2.3522", "", -1, "Europe/Paris", ErrInvalidValue},
+		{"TZ=UTC rate(5 minutes)", "", -1, "UTC", ErrInvalidValue},
+		{"CRON_TZ=Europe/Paris OnCalendar=daily UTC", "", -1, "UTC", ErrInvalidValue},
+	}
+
+	for _, c := range errs {
+		_, err := Parse(c.spec)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q => (expected) a *ParseError != %v (actual)", c.spec, err)
+			continue
+		}
+		if perr.Spec != c.spec || perr.Field != c.field || perr.Index != c.index || perr.Token != c.token {
+			t.Errorf("%q => (expected) %q %q %d %q != %q %q %d %q (actual)", c.spec,
+				c.spec, c.field, c.index, c.token, perr.Spec, perr.Field, perr.Index, perr.Token)
+		}
+		if !errors.Is(err, c.kind) {
+			t.Errorf("%q => (expected) %v != %v (actual)", c.spec, c.kind, perr.Kind)
+		}
+		if err.Error() == "" {
+			t.Errorf("%q => empty error message", c.spec)
+		}
+	}
+
+	if output.Len() > 0 {
+		t.Errorf("unexpected log output: %s", output.String())
+	}
+}
+
+func TestParser(t *testing.T) {
+	every5min := &SpecSchedule{Second: 1, Minute: getBits(0, 59, 5) | starBit, Hour: all(hours), Dom: all(dom), Month: all(months), Dow: all(dow)}
+	every5minOnMonday := &SpecSchedule{Second: 1, Minute: getBits(0, 59, 5) | starBit, Hour: all(hours), Dom: all(dom), Month: all(months), Dow: 1 << 1}
+	everySecondOnMonday := &SpecSchedule{Second: all(seconds), Minute: getBits(0, 59, 5) | starBit, Hour: all(hours), Dom: all(dom), Month: all(months), Dow: 1 << 1}
+
+	entries := []struct {
+		opts     []ParserOpt
+		spec     string
+		expected Schedule
+	}{
+		// Standard layout
+		{[]ParserOpt{WithStandardLayout()}, "*/5 * * * *", every5min},
+		{[]ParserOpt{WithStandardLayout()}, "*/5 * * * MON", every5minOnMonday},
+		{[]ParserOpt{WithStandardLayout()}, "* */5 * * * MON", nil},
+		{[]ParserOpt{WithStandardLayout()}, "*/5 * * *", nil},
+		{[]ParserOpt{WithStandardLayout()}, "@hourly", &SpecSchedule{Second: 1, Minute: 1, Hour: all(hours), Dom: all(dom), Month: all(months), Dow: all(dow)}},
+
+		// Optional seconds
+		{[]ParserOpt{WithStandardLayout(), WithSecondsField(FieldOptional)}, "*/5 * * * MON", every5minOnMonday},
+		{[]ParserOpt{WithStandardLayout(), WithSecondsField(FieldOptional)}, "* */5 * * * MON", everySecondOnMonday},
+
+		// Required day of week
+		{[]ParserOpt{WithDowField(FieldRequired)}, "0 */5 * * * MON", every5minOnMonday},
+		{[]ParserOpt{WithDowField(FieldRequired)}, "0 */5 * * *", nil},
+
+		// Unused year
+		{[]ParserOpt{WithYearField(FieldUnused)}, "0 */5 * * * MON", every5minOnMonday},
+		{[]ParserOpt{WithYearField(FieldUnused)}, "0 */5 * * * MON 2027", nil},
+
+		// Descriptors
+		{[]ParserOpt{WithDescriptors(false)}, "@hourly", nil},
+		{[]ParserOpt{WithDescriptors(false)}, "0 */5 * * * *", every5min},
+
+		// Horizon
+		{[]ParserOpt{WithHorizon(30)}, "0 */5 * * * *", &SpecSchedule{Second: 1, Minute: getBits(0, 59, 5) | starBit, Hour: all(hours), Dom: all(dom), Month: all(months), Dow: all(dow), Horizon: 30}},
+		{[]ParserOpt{WithDSTPolicy(DSTShiftMissing | DSTFirstOccurrence)}, "0 30 2 * * *", &SpecSchedule{Second: 1, Minute: 1 << 30, Hour: 1 << 2, Dom: all(dom), Month: all(months), Dow: all(dow), DST: DSTShiftMissing | DSTFirstOccurrence}},
+	}
+
+	for _, c := range entries {
+		parser, err := NewParser(c.opts...)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual, err := parser.Parse(c.spec)
+		if c.expected == nil {
+			if err == nil {
+				t.Error("expected an error parsing: ", c.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s => (expected) %v != %v (actual)", c.spec, c.expected, actual)
+		}
+	}
+
+	// The index of the field in error is the one in the spec.
+	parser, _ := NewParser(WithStandardLayout())
+	_, err := parser.Parse("60 * * * *")
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Field != "minute" || perr.Index != 0 {
+		t.Errorf("(expected) minute field at index 0 != %v (actual)", err)
+	}
+}
+
+func TestNewParserAmbiguous(t *testing.T) {
+	ambiguous := [][]ParserOpt{
+		{WithSecondsField(FieldOptional)},
+		{WithStandardLayout(), WithSecondsField(FieldOptional), WithYearField(FieldOptional)},
+		{WithStandardLayout(), WithSecondsField(FieldOptional), WithDowField(FieldOptional)},
+	}
+	for _, opts := range ambiguous {
+		if _, err := NewParser(opts...); err == nil {
+			t.Errorf("expected an error for an ambiguous layout")
+		}
+	}
+}
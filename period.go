@@ -0,0 +1,121 @@
+package etcdcron
+
+import (
+	"strconv"
+	"time"
+)
+
+// PeriodSchedule restricts the activation times of a schedule to every
+// Interval-th day, week, month or year, counted from the one containing the
+// anchor, e.g. "every other week" or "every 3 months".
+type PeriodSchedule struct {
+	Schedule Schedule
+	// Period is Daily, Weekly, Monthly or Yearly. The weeks start on Monday.
+	Period Frequency
+	// Interval is the number of periods from an active one to the next one.
+	Interval int
+	// Anchor is a time within the first active period. Its location is the
+	// one in which the periods are counted. There is no activation time
+	// before the first active period.
+	Anchor time.Time
+}
+
+// EveryPeriod returns a Schedule activated at the activation times of the
+// given schedule within every interval-th period from the one containing the
+// anchor. For example, every other Monday at 09:00 from January 5th 2026:
+//
+//	monday, _ := Parse("0 0 9 * * MON")
+//	EveryPeriod(monday, Weekly, 2, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC))
+//
+// An interval lower than 1 is taken as 1.
+func EveryPeriod(schedule Schedule, period Frequency, interval int, anchor time.Time) *PeriodSchedule {
+	if interval < 1 {
+		interval = 1
+	}
+	return &PeriodSchedule{Schedule: schedule, Period: period, Interval: interval, Anchor: anchor}
+}
+
+// String returns the schedule in the form of the call to EveryPeriod, e.g.
+// "EveryPeriod(0 0 9 * * 1; WEEKLY; 2; 2026-01-05T00:00:00Z)". It can't be
+// parsed.
+func (p *PeriodSchedule) String() string {
+	return "EveryPeriod(" + scheduleString(p.Schedule) + "; " + p.Period.String() + "; " + strconv.Itoa(p.Interval) + "; " + p.Anchor.Format(time.RFC3339) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler, but always returns an error:
+// the String of the schedule can't be parsed.
+func (p *PeriodSchedule) MarshalText() ([]byte, error) {
+	return nil, unparsableError(p)
+}
+
+// Next returns the next activation time of the schedule later than the given
+// time within an active period, or the zero time if there is none, the period
+// is not supported, or it couldn't be found in a reasonable number of steps.
+func (p *PeriodSchedule) Next(t time.Time) time.Time {
+	if p.Period < Daily || p.Period > Yearly {
+		return time.Time{}
+	}
+	interval := p.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	next := t
+	for step := 0; step < maxCombinatorSteps; step++ {
+		next = p.Schedule.Next(next)
+		if next.IsZero() {
+			return next
+		}
+		k := p.index(next)
+		if k >= 0 && k%interval == 0 {
+			return next
+		}
+		// Skip to the beginning of the next active period.
+		if k < 0 {
+			k = 0
+		} else {
+			k += interval - k%interval
+		}
+		next = p.start(k).Add(-time.Nanosecond).In(t.Location())
+	}
+	return time.Time{}
+}
+
+// index returns the number of periods from the one containing the anchor to
+// the one containing the given time, negative if it's before.
+func (p *PeriodSchedule) index(t time.Time) int {
+	t = t.In(p.Anchor.Location())
+	switch p.Period {
+	case Daily:
+		return civilDay(t) - civilDay(p.Anchor)
+	case Weekly:
+		return (civilDay(weekStart(t, time.Monday)) - civilDay(weekStart(p.Anchor, time.Monday))) / 7
+	case Monthly:
+		return (t.Year()-p.Anchor.Year())*12 + int(t.Month()-p.Anchor.Month())
+	default:
+		return t.Year() - p.Anchor.Year()
+	}
+}
+
+// start returns the beginning of the k-th period from the one containing the
+// anchor.
+func (p *PeriodSchedule) start(k int) time.Time {
+	a, loc := p.Anchor, p.Anchor.Location()
+	switch p.Period {
+	case Daily:
+		return time.Date(a.Year(), a.Month(), a.Day()+k, 0, 0, 0, 0, loc)
+	case Weekly:
+		monday := weekStart(a, time.Monday)
+		return time.Date(monday.Year(), monday.Month(), monday.Day()+7*k, 0, 0, 0, 0, loc)
+	case Monthly:
+		return time.Date(a.Year(), a.Month()+time.Month(k), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(a.Year()+k, time.January, 1, 0, 0, 0, 0, loc)
+	}
+}
+
+// civilDay returns the number of days from January 1st 1970 to the date of
+// the given time, in its location.
+func civilDay(t time.Time) int {
+	return floorDiv(int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix()), 24*60*60)
+}
@@ -0,0 +1,78 @@
+package etcdcron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodScheduleNext(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	monday := mustParse(t, "0 0 9 * * MON")
+	mondayAndFriday := mustParse(t, "0 0 9 * * MON,FRI")
+	first := mustParse(t, "0 0 0 1 * *")
+	morning := mustParse(t, "0 30 8 * * *")
+
+	tests := []struct {
+		schedule Schedule
+		time     string
+		expected string
+	}{
+		// Every other Monday.
+		{EveryPeriod(monday, Weekly, 2, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)), "2026-01-01T00:00:00Z", "2026-01-05T09:00:00Z"},
+		{EveryPeriod(monday, Weekly, 2, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)), "2026-01-05T09:00:00Z", "2026-01-19T09:00:00Z"},
+		{EveryPeriod(monday, Weekly, 2, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)), "2026-10-13T00:00:00Z", "2026-10-26T09:00:00Z"},
+
+		// The period containing the anchor is active, from its beginning.
+		{EveryPeriod(mondayAndFriday, Weekly, 3, time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)), "2026-01-01T00:00:00Z", "2026-01-05T09:00:00Z"},
+		{EveryPeriod(mondayAndFriday, Weekly, 3, time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)), "2026-01-05T09:00:00Z", "2026-01-09T09:00:00Z"},
+		{EveryPeriod(mondayAndFriday, Weekly, 3, time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)), "2026-01-09T09:00:00Z", "2026-01-26T09:00:00Z"},
+
+		// Every 3 months on the 1st, from March 2026.
+		{EveryPeriod(first, Monthly, 3, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)), "2025-11-15T00:00:00Z", "2026-03-01T00:00:00Z"},
+		{EveryPeriod(first, Monthly, 3, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)), "2026-03-01T00:00:00Z", "2026-06-01T00:00:00Z"},
+		{EveryPeriod(first, Monthly, 3, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)), "2026-10-17T10:00:00Z", "2026-12-01T00:00:00Z"},
+
+		// Every 3 days, and every other year.
+		{EveryPeriod(morning, Daily, 3, time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)), "2026-10-17T09:00:00Z", "2026-10-20T08:30:00Z"},
+		{EveryPeriod(morning, Daily, 3, time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)), "2026-10-29T09:00:00Z", "2026-11-01T08:30:00Z"},
+		{EveryPeriod(first, Yearly, 2, time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)), "2026-10-17T10:00:00Z", "2027-01-01T00:00:00Z"},
+		{EveryPeriod(first, Yearly, 2, time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)), "2027-12-01T00:00:00Z", "2029-01-01T00:00:00Z"},
+
+		// The periods are counted in the location of the anchor: 23:30 UTC on
+		// Sunday is already Monday in Paris.
+		{EveryPeriod(mustParse(t, "0 30 23 * * SUN"), Weekly, 2, time.Date(2026, 1, 5, 0, 0, 0, 0, paris)), "2026-01-05T00:00:00Z", "2026-01-18T23:30:00Z"},
+		{EveryPeriod(mustParse(t, "0 30 23 * * SUN"), Weekly, 2, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)), "2026-01-05T00:00:00Z", "2026-01-11T23:30:00Z"},
+
+		// Before 1970.
+		{EveryPeriod(morning, Daily, 3, time.Date(1969, 12, 30, 12, 0, 0, 0, time.UTC)), "1969-12-30T09:00:00Z", "1970-01-02T08:30:00Z"},
+		{EveryPeriod(monday, Weekly, 2, time.Date(1969, 12, 29, 0, 0, 0, 0, time.UTC)), "1969-12-01T00:00:00Z", "1969-12-29T09:00:00Z"},
+		{EveryPeriod(monday, Weekly, 2, time.Date(1969, 12, 29, 0, 0, 0, 0, time.UTC)), "1969-12-29T09:00:00Z", "1970-01-12T09:00:00Z"},
+
+		// Unsupported periods.
+		{EveryPeriod(morning, Hourly, 2, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)), "2026-10-17T00:00:00Z", ""},
+	}
+
+	for _, c := range tests {
+		from, _ := time.Parse(time.RFC3339, c.time)
+		var expected time.Time
+		if c.expected != "" {
+			expected, _ = time.Parse(time.RFC3339, c.expected)
+		}
+		actual := c.schedule.Next(from)
+		if !actual.Equal(expected) {
+			p := c.schedule.(*PeriodSchedule)
+			t.Errorf("%v/%d from %v, %s: (expected) %v != %v (actual)", p.Period, p.Interval, p.Anchor, c.time, expected, actual)
+		}
+	}
+}
+
+func TestPeriodScheduleString(t *testing.T) {
+	monday := mustParse(t, "0 0 9 * * MON")
+	schedule := EveryPeriod(monday, Weekly, 2, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC))
+	if actual, expected := schedule.String(), "EveryPeriod(0 0 9 * * 1; WEEKLY; 2; 2026-01-05T00:00:00Z)"; actual != expected {
+		t.Errorf("(expected) %q != %q (actual)", expected, actual)
+	}
+}
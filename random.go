@@ -0,0 +1,195 @@
+package etcdcron
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RandomSchedule is activated once per day or per week, at a pseudo-random
+// time within a daily window, e.g. "once per day between 02:00 and 04:00". The
+// time of each period is derived from the key and from the period itself.
+type RandomSchedule struct {
+	// Start and End bound the window, as durations from midnight on the wall
+	// clock. End is excluded. If End is not after Start, the window ends on
+	// the next day.
+	Start, End time.Duration
+	// Period is Daily or Weekly. The weeks start on Monday, and the day of the
+	// week is also pseudo-random.
+	Period Frequency
+	// Key, usually the name of the job, makes the times of the schedules with
+	// the same window differ.
+	Key string
+	// Location overrides the time zone in which the schedule is evaluated. If
+	// nil, the location of the time given to Next is used.
+	Location *time.Location
+}
+
+// Random returns a Schedule activated once per period (Daily or Weekly), at a
+// time between start and end derived from the key. The "@random" descriptor
+// is keyed by the name of the job.
+func Random(start, end time.Duration, period Frequency, key string) *RandomSchedule {
+	return &RandomSchedule{Start: start, End: end, Period: period, Key: key}
+}
+
+// Next returns the activation time later than the given time, or the zero time
+// if the period is not supported.
+func (s *RandomSchedule) Next(t time.Time) time.Time {
+	if s.Period != Daily && s.Period != Weekly {
+		return time.Time{}
+	}
+	origLocation := t.Location()
+	if s.Location != nil {
+		t = t.In(s.Location)
+	}
+
+	// The activation time of the previous period may be later than t, if its
+	// window ends on the next day.
+	period := s.period(t)
+	for p := period - 1; p <= period+1; p++ {
+		if activation := s.activation(p, t.Location()); activation.After(t) {
+			return activation.In(origLocation)
+		}
+	}
+	return time.Time{}
+}
+
+// period returns the number of the day or week of the given time, from
+// January 1st 1970 or from the week starting on Monday, December 29th 1969.
+func (s *RandomSchedule) period(t time.Time) int {
+	day := civilDay(t)
+	if s.Period == Weekly {
+		return floorDiv(day+3, 7)
+	}
+	return day
+}
+
+// activation returns the activation time of the given period.
+func (s *RandomSchedule) activation(period int, loc *time.Location) time.Time {
+	h := fnv.New64a()
+	h.Write([]byte(s.Key))
+	var p [8]byte
+	binary.BigEndian.PutUint64(p[:], uint64(period))
+	h.Write(p[:])
+	hash := h.Sum64()
+
+	day := period
+	if s.Period == Weekly {
+		day = period*7 - 3 + int(hash%7)
+		hash /= 7
+	}
+	window := s.End - s.Start
+	if window <= 0 {
+		window += 24 * time.Hour
+	}
+	offset := time.Duration(0)
+	if seconds := uint64(window / time.Second); seconds > 0 {
+		offset = time.Duration(hash%seconds) * time.Second
+	}
+
+	// The time is set on the wall clock, as the cron expressions are.
+	year, month, date := time.Unix(int64(day)*24*60*60, 0).UTC().Date()
+	return time.Date(year, month, date, 0, 0, int((s.Start+offset)/time.Second), 0, loc)
+}
+
+// floorDiv returns a divided by b, rounded down.
+func floorDiv(a, b int) int {
+	if a < 0 && a%b != 0 {
+		return a/b - 1
+	}
+	return a / b
+}
+
+// String returns the descriptor of the schedule, e.g. "@random 02:00-04:00
+// daily" or "@random 02:00-04:00 daily key "backup"", prefixed by its time
+// zone if any.
+func (s *RandomSchedule) String() string {
+	spec := fmt.Sprintf("@random %s-%s %s", formatClock(s.Start), formatClock(s.End), strings.ToLower(s.Period.String()))
+	if s.Key != "" {
+		spec += " key " + strconv.Quote(s.Key)
+	}
+	if s.Location != nil {
+		spec = "CRON_TZ=" + s.Location.String() + " " + spec
+	}
+	return spec
+}
+
+// formatClock formats a duration from midnight as a time of day, with the
+// seconds if any.
+func formatClock(d time.Duration) string {
+	clock := time.Date(0, time.January, 1, 0, 0, 0, 0, time.UTC).Add(d)
+	if clock.Second() != 0 {
+		return clock.Format("15:04:05")
+	}
+	return clock.Format("15:04")
+}
+
+// MarshalText implements encoding.TextMarshaler, using String.
+func (s *RandomSchedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, using Parse.
+func (s *RandomSchedule) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	random, ok := parsed.(*RandomSchedule)
+	if !ok {
+		return unexpectedScheduleError(text, parsed)
+	}
+	*s = *random
+	return nil
+}
+
+// parseRandom parses the arguments of a "@random" descriptor: a window
+// "HH:MM-HH:MM", the times possibly with seconds, optionally followed by
+// "daily" (the default) or "weekly", and by "key" and a quoted key which
+// replaces the given one.
+func parseRandom(args, key string) (*RandomSchedule, *ParseError) {
+	if value, quoted, ok := strings.Cut(args, " key "); ok {
+		quoted = strings.TrimSpace(quoted)
+		unquoted, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, parseErrorf(ErrInvalidValue, quoted, "expected a quoted key")
+		}
+		args, key = value, unquoted
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) < 1 || len(fields) > 2 {
+		return nil, parseErrorf(ErrInvalidValue, args, "expected HH:MM-HH:MM [daily|weekly]")
+	}
+
+	bounds := strings.Split(fields[0], "-")
+	if len(bounds) != 2 {
+		return nil, parseErrorf(ErrBadRange, fields[0], "expected HH:MM-HH:MM")
+	}
+	var window [2]time.Duration
+	for i, bound := range bounds {
+		clock, err := time.Parse("15:04:05", bound)
+		if err != nil {
+			clock, err = time.Parse("15:04", bound)
+		}
+		if err != nil {
+			return nil, parseErrorf(ErrInvalidValue, bound, "expected a time of day HH:MM or HH:MM:SS")
+		}
+		window[i] = time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute + time.Duration(clock.Second())*time.Second
+	}
+
+	period := Daily
+	if len(fields) == 2 {
+		switch strings.ToLower(fields[1]) {
+		case "daily":
+		case "weekly":
+			period = Weekly
+		default:
+			return nil, parseErrorf(ErrInvalidValue, fields[1], "expected daily or weekly")
+		}
+	}
+	return Random(window[0], window[1], period, key), nil
+}
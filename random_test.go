@@ -0,0 +1,172 @@
+package etcdcron
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRandomScheduleNext(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		schedule *RandomSchedule
+		from     time.Time
+		period   func(time.Time) int
+		inWindow func(time.Time) bool
+	}{
+		{
+			"daily",
+			Random(2*time.Hour, 4*time.Hour, Daily, "backup"),
+			time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			civilDay,
+			func(t time.Time) bool { return t.Hour() >= 2 && t.Hour() < 4 },
+		},
+		{
+			"across midnight",
+			Random(23*time.Hour, time.Hour, Daily, "backup"),
+			time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			func(t time.Time) int { return civilDay(t.Add(-23 * time.Hour)) },
+			func(t time.Time) bool { return t.Hour() == 23 || t.Hour() == 0 },
+		},
+		{
+			"weekly",
+			Random(2*time.Hour, 4*time.Hour, Weekly, "backup"),
+			time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			func(t time.Time) int { return civilDay(weekStart(t, time.Monday)) },
+			func(t time.Time) bool { return t.Hour() >= 2 && t.Hour() < 4 },
+		},
+		{
+			"before 1970",
+			Random(2*time.Hour, 4*time.Hour, Weekly, "backup"),
+			time.Date(1969, 10, 1, 0, 0, 0, 0, time.UTC),
+			func(t time.Time) int { return civilDay(weekStart(t, time.Monday)) },
+			func(t time.Time) bool { return t.Hour() >= 2 && t.Hour() < 4 },
+		},
+		{
+			"location",
+			&RandomSchedule{Start: 2 * time.Hour, End: 4 * time.Hour, Period: Daily, Key: "backup", Location: paris},
+			time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			func(t time.Time) int { return civilDay(t.In(paris)) },
+			func(t time.Time) bool { return t.In(paris).Hour() >= 2 && t.In(paris).Hour() < 4 },
+		},
+	}
+
+	for _, c := range tests {
+		// Exactly one activation time per period, within the window, and
+		// in the location of the given time.
+		activation := c.schedule.Next(c.from)
+		previous := c.period(activation) - 1
+		if c.schedule.Period == Weekly {
+			previous = c.period(activation) - 7
+		}
+		times := map[string]bool{}
+		for i := 0; i < 200; i++ {
+			if !c.inWindow(activation) {
+				t.Errorf("%s: %v is not within the window", c.name, activation)
+			}
+			if activation.Location() != time.UTC {
+				t.Errorf("%s: %v is not in UTC", c.name, activation)
+			}
+			if period := c.period(activation); period == previous {
+				t.Errorf("%s: more than one activation time in the period of %v", c.name, activation)
+			} else if period > previous+7 || (c.schedule.Period == Daily && period > previous+1) {
+				t.Errorf("%s: missing an activation time before %v", c.name, activation)
+			} else {
+				previous = period
+			}
+			times[activation.Format("15:04:05")] = true
+
+			// Any time within the period gives the same activation time.
+			if next := c.schedule.Next(activation.Add(-time.Second)); !next.Equal(activation) {
+				t.Errorf("%s: %v => (expected) %v != %v (actual)", c.name, activation.Add(-time.Second), activation, next)
+			}
+			activation = c.schedule.Next(activation)
+		}
+
+		// The time within the window changes from one period to the next.
+		if len(times) < 100 {
+			t.Errorf("%s: only %d different times over 200 periods", c.name, len(times))
+		}
+	}
+}
+
+func TestRandomScheduleKey(t *testing.T) {
+	from := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	// The same key gives the same times, e.g. on every node of the cluster.
+	first, _ := ParseHashed("@random 02:00-04:00 daily", "backup")
+	second, _ := ParseHashed("@random 02:00-04:00 daily", "backup")
+	for i := 0; i < 10; i++ {
+		next := first.Next(from)
+		if actual := second.Next(from); !actual.Equal(next) {
+			t.Errorf("%v => (expected) %v != %v (actual)", from, next, actual)
+		}
+		from = next
+	}
+
+	// Different keys spread their times over the window.
+	from = time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	times := map[time.Time]bool{}
+	for i := 0; i < 50; i++ {
+		schedule, err := ParseHashed("@random 02:00-04:00 daily", fmt.Sprintf("job-%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		times[schedule.Next(from)] = true
+	}
+	if len(times) < 45 {
+		t.Errorf("only %d different times for 50 keys", len(times))
+	}
+}
+
+func TestRandomScheduleParse(t *testing.T) {
+	schedule, err := ParseHashed("CRON_TZ=Europe/Paris @random 22:00-23:00 weekly", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	random, ok := schedule.(*RandomSchedule)
+	if !ok {
+		t.Fatalf("(expected) *RandomSchedule != %T (actual)", schedule)
+	}
+	if random.Start != 22*time.Hour || random.End != 23*time.Hour || random.Period != Weekly ||
+		random.Key != "backup" || random.Location.String() != "Europe/Paris" {
+		t.Errorf("unexpected schedule %+v", random)
+	}
+
+	// A one-second window leaves no choice.
+	schedule = mustParse(t, "@random 12:00:00-12:00:01")
+	from := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	if next, expected := schedule.Next(from), from.AddDate(0, 0, 1); !next.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, next)
+	}
+}
+
+func TestRandomScheduleString(t *testing.T) {
+	schedule, err := ParseHashed("@random 02:00-04:00 weekly", "backup job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := schedule.(*RandomSchedule).String()
+	if expected := `@random 02:00-04:00 weekly key "backup job"`; spec != expected {
+		t.Fatalf("(expected) %q != %q (actual)", expected, spec)
+	}
+
+	// The key of the spec replaces the one given to ParseHashed.
+	parsed, err := ParseHashed(spec, "other job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		next := schedule.Next(from)
+		if actual := parsed.Next(from); !actual.Equal(next) {
+			t.Fatalf("%v => (expected) %v != %v (actual)", from, next, actual)
+		}
+		from = next
+	}
+}
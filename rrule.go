@@ -0,0 +1,870 @@
+package etcdcron
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the base period of a recurrence rule.
+type Frequency int
+
+const (
+	Secondly Frequency = iota
+	Minutely
+	Hourly
+	Daily
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = []string{"SECONDLY", "MINUTELY", "HOURLY", "DAILY", "WEEKLY", "MONTHLY", "YEARLY"}
+
+func (f Frequency) String() string {
+	if f < Secondly || f > Yearly {
+		return "Frequency(" + strconv.Itoa(int(f)) + ")"
+	}
+	return frequencyNames[f]
+}
+
+// RRuleWeekday is a day of the BYDAY part of a recurrence rule, e.g. "MO" or
+// "-1FR". N is the occurrence of the day within the month or the year, from
+// the end if negative, or 0 for every occurrence.
+type RRuleWeekday struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// RRuleSchedule is a recurrence rule as defined by RFC 5545 (iCalendar), e.g.
+// "every 2 weeks on Monday and Thursday until 2027-06-30".
+//
+// Start, Until and Exdates are wall clock times in Location. If Location is
+// nil, they are floating times: their wall clock is interpreted in the time
+// zone of the time given to Next.
+type RRuleSchedule struct {
+	// Start is the DTSTART of the rule: the first occurrence, which also
+	// provides the default values of the BYxxx parts.
+	Start    time.Time
+	Location *time.Location
+
+	Freq     Frequency
+	Interval int
+	// Count limits the number of occurrences, 0 if unlimited.
+	Count int
+	// Until is the last possible occurrence, zero if unlimited.
+	Until time.Time
+	Wkst  time.Weekday
+
+	BySecond, ByMinute, ByHour []int
+	ByDay                      []RRuleWeekday
+	ByMonthDay                 []int
+	ByYearDay                  []int
+	ByWeekNo                   []int
+	ByMonth                    []int
+	BySetPos                   []int
+
+	// Exdates are the occurrences excluded from the recurrence set.
+	Exdates []time.Time
+
+	// Horizon is the number of years Next looks ahead for an occurrence before
+	// giving up. If zero, DefaultHorizon is used.
+	Horizon int
+}
+
+var rruleWeekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// ParseRRule returns the recurrence rule described by the given iCalendar
+// content lines, e.g.:
+//
+//	DTSTART;TZID=Europe/Paris:20260105T090000
+//	RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20270630T235959Z
+//	EXDATE;TZID=Europe/Paris:20260202T090000
+//
+// Lines can be separated by new lines or spaces. Without DTSTART, the rule
+// starts on January 1st, 1970 at midnight, floating time, and can't have a
+// COUNT.
+// It returns a *ParseError if the rule is not valid.
+func ParseRRule(spec string) (*RRuleSchedule, error) {
+	schedule, err := parseRRule(spec, nil)
+	if err != nil {
+		err.Spec = spec
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// parseRRule parses the content lines of a rule. The floating times of the rule
+// are wall clock times in the given location, if not nil.
+func parseRRule(spec string, floatingLoc *time.Location) (*RRuleSchedule, *ParseError) {
+	s := &RRuleSchedule{
+		Start:    time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Interval: 1,
+		Wkst:     time.Monday,
+	}
+
+	var rule, until string
+	var exdateLocations []*time.Location
+	floating, hasStart := true, false
+	for _, line := range strings.Fields(spec) {
+		colon := strings.Index(line, ":")
+		if colon == -1 {
+			if strings.HasPrefix(line, "FREQ=") {
+				rule = line
+				continue
+			}
+			return nil, parseErrorf(ErrInvalidValue, line, "expected an iCalendar property")
+		}
+		name, params, value := line[:colon], "", line[colon+1:]
+		if semicolon := strings.Index(name, ";"); semicolon != -1 {
+			name, params = name[:semicolon], name[semicolon+1:]
+		}
+
+		switch strings.ToUpper(name) {
+		case "DTSTART":
+			start, loc, err := parseICalTime(value, params)
+			if err != nil {
+				err.Field = "DTSTART"
+				return nil, err
+			}
+			s.Start, s.Location = start, loc
+			floating, hasStart = loc == nil, true
+		case "RRULE":
+			rule = value
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				exdate, loc, err := parseICalTime(v, params)
+				if err != nil {
+					err.Field = "EXDATE"
+					return nil, err
+				}
+				s.Exdates = append(s.Exdates, exdate)
+				exdateLocations = append(exdateLocations, loc)
+			}
+		default:
+			return nil, parseErrorf(ErrInvalidValue, name, "unsupported iCalendar property")
+		}
+	}
+	if rule == "" {
+		return nil, parseErrorf(ErrEmptySpec, "", "missing RRULE")
+	}
+	if floating && floatingLoc != nil {
+		s.Start = inWallClock(s.Start, floatingLoc)
+		s.Location = floatingLoc
+		floating = false
+	}
+
+	// Express the excluded dates as wall clock times of the rule.
+	for i, exdate := range s.Exdates {
+		switch {
+		case floating:
+		case exdateLocations[i] == nil:
+			s.Exdates[i] = inWallClock(exdate, s.Location)
+		default:
+			s.Exdates[i] = exdate.In(s.Location)
+		}
+	}
+
+	freq := false
+	for _, part := range strings.Split(rule, ";") {
+		nameAndValue := strings.SplitN(part, "=", 2)
+		if len(nameAndValue) != 2 {
+			return nil, parseErrorf(ErrInvalidValue, part, "expected NAME=VALUE")
+		}
+		name, value := strings.ToUpper(nameAndValue[0]), nameAndValue[1]
+
+		var err *ParseError
+		switch name {
+		case "FREQ":
+			freq = true
+			err = parseErrorf(ErrInvalidValue, value, "unknown frequency")
+			for f, fname := range frequencyNames {
+				if strings.ToUpper(value) == fname {
+					s.Freq, err = Frequency(f), nil
+				}
+			}
+		case "INTERVAL":
+			s.Interval, err = parseRRuleInt(value, 1, 0)
+		case "COUNT":
+			s.Count, err = parseRRuleInt(value, 1, 0)
+		case "UNTIL":
+			until = value
+		case "WKST":
+			wkst, ok := rruleWeekdays[strings.ToUpper(value)]
+			if !ok {
+				err = parseErrorf(ErrInvalidValue, value, "unknown day of week")
+			}
+			s.Wkst = wkst
+		case "BYSECOND":
+			s.BySecond, err = parseRRuleInts(value, 0, 60, false)
+		case "BYMINUTE":
+			s.ByMinute, err = parseRRuleInts(value, 0, 59, false)
+		case "BYHOUR":
+			s.ByHour, err = parseRRuleInts(value, 0, 23, false)
+		case "BYDAY":
+			s.ByDay, err = parseRRuleWeekdays(value)
+		case "BYMONTHDAY":
+			s.ByMonthDay, err = parseRRuleInts(value, 1, 31, true)
+		case "BYYEARDAY":
+			s.ByYearDay, err = parseRRuleInts(value, 1, 366, true)
+		case "BYWEEKNO":
+			s.ByWeekNo, err = parseRRuleInts(value, 1, 53, true)
+		case "BYMONTH":
+			s.ByMonth, err = parseRRuleInts(value, 1, 12, false)
+		case "BYSETPOS":
+			s.BySetPos, err = parseRRuleInts(value, 1, 366, true)
+		default:
+			err = parseErrorf(ErrInvalidValue, part, "unknown rule part")
+		}
+		if err != nil {
+			err.Field = name
+			return nil, err
+		}
+	}
+	if !freq {
+		return nil, parseErrorf(ErrInvalidValue, rule, "missing FREQ")
+	}
+
+	if until != "" {
+		if s.Count > 0 {
+			return nil, parseErrorf(ErrInvalidValue, until, "COUNT and UNTIL are exclusive")
+		}
+		u, loc, err := parseICalTime(until, "")
+		if err != nil {
+			err.Field = "UNTIL"
+			return nil, err
+		}
+		// A UTC bound of a floating rule is kept as a wall clock time.
+		switch {
+		case floating:
+		case loc == nil:
+			u = inWallClock(u, s.Location)
+		default:
+			u = u.In(s.Location)
+		}
+		s.Until = u
+	}
+	// The occurrences are counted from DTSTART: from 1970, they would have run
+	// out long ago.
+	if s.Count > 0 && !hasStart {
+		err := parseErrorf(ErrInvalidValue, rule, "COUNT requires a DTSTART")
+		err.Field = "COUNT"
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// parseICalTime parses an iCalendar DATE or DATE-TIME value with its
+// parameters. It returns the location of the time, nil for floating times.
+func parseICalTime(value, params string) (time.Time, *time.Location, *ParseError) {
+	var loc *time.Location
+	for _, param := range strings.Split(params, ";") {
+		if strings.HasPrefix(strings.ToUpper(param), "TZID=") {
+			var err error
+			loc, err = time.LoadLocation(param[len("TZID="):])
+			if err != nil {
+				return time.Time{}, nil, parseErrorf(ErrUnknownLocation, param[len("TZID="):], "%v", err)
+			}
+		}
+	}
+	if strings.HasSuffix(value, "Z") {
+		loc = time.UTC
+		value = value[:len(value)-1]
+	}
+
+	layout := "20060102T150405"
+	if len(value) == len("20060102") {
+		layout = "20060102"
+	}
+	parseLoc := loc
+	if parseLoc == nil {
+		parseLoc = time.UTC
+	}
+	t, err := time.ParseInLocation(layout, value, parseLoc)
+	if err != nil {
+		return time.Time{}, nil, parseErrorf(ErrInvalidValue, value, "expected a date or a date-time")
+	}
+	return t, loc, nil
+}
+
+// parseRRuleInt parses a number of a rule part, within the given bounds (max
+// is ignored if 0).
+func parseRRuleInt(value string, min, max int) (int, *ParseError) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, parseErrorf(ErrInvalidValue, value, "not a number")
+	}
+	if n < min || max > 0 && n > max {
+		return 0, parseErrorf(ErrOutOfRange, value, "not in %d-%d", min, max)
+	}
+	return n, nil
+}
+
+// parseRRuleInts parses a comma-separated list of numbers of a rule part,
+// within the given bounds, or their opposite if negative values are allowed.
+func parseRRuleInts(value string, min, max int, negative bool) ([]int, *ParseError) {
+	var result []int
+	for _, v := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimPrefix(v, "+"))
+		if err != nil {
+			return nil, parseErrorf(ErrInvalidValue, v, "not a number")
+		}
+		abs := n
+		if negative && n < 0 {
+			abs = -n
+		}
+		if abs < min || abs > max {
+			return nil, parseErrorf(ErrOutOfRange, v, "not in %d-%d", min, max)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// parseRRuleWeekdays parses the value of the BYDAY rule part.
+func parseRRuleWeekdays(value string) ([]RRuleWeekday, *ParseError) {
+	var result []RRuleWeekday
+	for _, v := range strings.Split(value, ",") {
+		if len(v) < 2 {
+			return nil, parseErrorf(ErrInvalidValue, v, "unknown day of week")
+		}
+		weekday, ok := rruleWeekdays[strings.ToUpper(v[len(v)-2:])]
+		if !ok {
+			return nil, parseErrorf(ErrInvalidValue, v, "unknown day of week")
+		}
+		var n int
+		if prefix := v[:len(v)-2]; prefix != "" {
+			ns, err := parseRRuleInts(prefix, 1, 53, true)
+			if err != nil {
+				err.Token = v
+				return nil, err
+			}
+			n = ns[0]
+		}
+		result = append(result, RRuleWeekday{Weekday: weekday, N: n})
+	}
+	return result, nil
+}
+
+// Next returns the next occurrence of the rule, later than the given time, or
+// the zero time if there is none within the horizon.
+func (s *RRuleSchedule) Next(t time.Time) time.Time {
+	loc := s.Location
+	if loc == nil {
+		loc = t.Location()
+	}
+	start := wallClock(s.Start)
+	interval := s.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	// Without COUNT, the occurrences before t don't matter: start from the
+	// period before the one of t. With COUNT, this is only possible if the
+	// number of occurrences of the skipped periods is known.
+	k, count := 0, 0
+	if k = s.periodIndex(start, wallClock(t.In(loc)))/interval - 1; k < 0 {
+		k = 0
+	}
+	if s.Count > 0 && k > 0 {
+		n, ok := s.periodCount()
+		if !ok {
+			k = 0
+		} else {
+			for _, w := range s.occurrences(start, s.periodStart(start, 0)) {
+				if !w.Before(start) {
+					count++
+				}
+			}
+			count += (k - 1) * n
+		}
+	}
+
+	horizon := s.Horizon
+	if horizon <= 0 {
+		horizon = DefaultHorizon
+	}
+	limit := t
+	if limit.Before(s.Start) {
+		limit = s.Start
+	}
+	limit = wallClock(limit.In(loc)).AddDate(horizon, 0, 0)
+	if periodLimit := s.periodStart(start, s.periodIndex(start, limit)+2*interval); s.Freq > Daily && limit.Before(periodLimit) {
+		limit = periodLimit
+	}
+
+	for {
+		period := s.periodStart(start, k*interval)
+		if period.After(limit) {
+			return time.Time{}
+		}
+
+		// For sub-daily frequencies, skip the days, hours or minutes which
+		// can't match at once.
+		if skipTo := s.skipTo(period); !skipTo.IsZero() {
+			next := (s.periodIndex(start, skipTo.Add(-time.Second)) + interval) / interval
+			if next > k {
+				k = next
+				continue
+			}
+		}
+
+		for _, w := range s.occurrences(start, period) {
+			if w.Before(start) {
+				continue
+			}
+			if !s.Until.IsZero() && w.After(wallClock(s.Until)) {
+				return time.Time{}
+			}
+			count++
+			if s.Count > 0 && count > s.Count {
+				return time.Time{}
+			}
+			if s.excluded(w) {
+				continue
+			}
+			occurrence := time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), 0, loc)
+			if occurrence.After(t) {
+				return occurrence.In(t.Location())
+			}
+		}
+		k++
+	}
+}
+
+// skipTo returns the beginning of the next day, hour or minute if the period
+// beginning at the given wall clock time is within a day, an hour or a minute
+// which doesn't match the rule, or the zero time otherwise.
+func (s *RRuleSchedule) skipTo(period time.Time) time.Time {
+	switch {
+	case s.Freq >= Daily:
+		return time.Time{}
+	case !s.dayMatches(period):
+		return time.Date(period.Year(), period.Month(), period.Day()+1, 0, 0, 0, 0, time.UTC)
+	case s.Freq < Hourly && len(s.ByHour) > 0 && !containsInt(s.ByHour, period.Hour()):
+		return period.Truncate(time.Hour).Add(time.Hour)
+	case s.Freq < Minutely && len(s.ByMinute) > 0 && !containsInt(s.ByMinute, period.Minute()):
+		return period.Truncate(time.Minute).Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+// periodCount returns the number of occurrences of every period of the rule,
+// or false if it depends on the period: if some part of the rule filters the
+// periods, or if the day of the start doesn't exist in every month or year.
+// The first period may have fewer occurrences, as the ones before the start
+// are ignored.
+func (s *RRuleSchedule) periodCount() (int, bool) {
+	if len(s.ByMonth) > 0 || len(s.ByWeekNo) > 0 || len(s.ByYearDay) > 0 || len(s.ByMonthDay) > 0 || len(s.BySetPos) > 0 {
+		return 0, false
+	}
+	n := 1
+	switch {
+	case s.Freq == Weekly && len(s.ByDay) > 0:
+		var weekdays [7]bool
+		n = 0
+		for _, wd := range s.ByDay {
+			if wd.N != 0 {
+				return 0, false
+			}
+			if !weekdays[wd.Weekday] {
+				weekdays[wd.Weekday] = true
+				n++
+			}
+		}
+	case len(s.ByDay) > 0:
+		return 0, false
+	case s.Freq == Monthly && s.Start.Day() > 28:
+		return 0, false
+	case s.Freq == Yearly && s.Start.Month() == time.February && s.Start.Day() == 29:
+		return 0, false
+	}
+	for _, part := range []struct {
+		by        []int
+		component Frequency
+	}{{s.ByHour, Hourly}, {s.ByMinute, Minutely}, {s.BySecond, Secondly}} {
+		if len(part.by) == 0 {
+			continue
+		}
+		if s.Freq <= part.component {
+			return 0, false
+		}
+		n *= len(part.by)
+	}
+	return n, true
+}
+
+// wallClock returns the wall clock of the given time, as a UTC time.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// inWallClock returns the time with the wall clock of the given time in the
+// given location.
+func inWallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+}
+
+// excluded returns true if the given wall clock time is one of the EXDATE of
+// the rule.
+func (s *RRuleSchedule) excluded(w time.Time) bool {
+	for _, exdate := range s.Exdates {
+		if wallClock(exdate).Equal(w) {
+			return true
+		}
+	}
+	return false
+}
+
+// periodIndex returns the number of periods of the rule frequency between the
+// period of start and the one of t (both wall clock times).
+func (s *RRuleSchedule) periodIndex(start, t time.Time) int {
+	if t.Before(start) {
+		return 0
+	}
+	switch s.Freq {
+	case Yearly:
+		return t.Year() - start.Year()
+	case Monthly:
+		return (t.Year()-start.Year())*12 + int(t.Month()) - int(start.Month())
+	case Weekly:
+		return int(weekStart(t, s.Wkst).Sub(weekStart(start, s.Wkst)).Hours()) / (24 * 7)
+	case Daily:
+		return int(truncateDay(t).Sub(truncateDay(start)).Hours()) / 24
+	case Hourly:
+		return int(t.Truncate(time.Hour).Sub(start.Truncate(time.Hour)) / time.Hour)
+	case Minutely:
+		return int(t.Truncate(time.Minute).Sub(start.Truncate(time.Minute)) / time.Minute)
+	default:
+		return int(t.Sub(start) / time.Second)
+	}
+}
+
+// periodStart returns the beginning of the k-th period following the one of
+// start (a wall clock time).
+func (s *RRuleSchedule) periodStart(start time.Time, k int) time.Time {
+	switch s.Freq {
+	case Yearly:
+		return time.Date(start.Year()+k, time.January, 1, 0, 0, 0, 0, time.UTC)
+	case Monthly:
+		return time.Date(start.Year(), start.Month()+time.Month(k), 1, 0, 0, 0, 0, time.UTC)
+	case Weekly:
+		return weekStart(start, s.Wkst).AddDate(0, 0, 7*k)
+	case Daily:
+		return truncateDay(start).AddDate(0, 0, k)
+	case Hourly:
+		return start.Truncate(time.Hour).Add(time.Duration(k) * time.Hour)
+	case Minutely:
+		return start.Truncate(time.Minute).Add(time.Duration(k) * time.Minute)
+	default:
+		return start.Add(time.Duration(k) * time.Second)
+	}
+}
+
+// occurrences returns the sorted occurrences of the period beginning at the
+// given wall clock time.
+func (s *RRuleSchedule) occurrences(start, period time.Time) []time.Time {
+	var days []time.Time
+	switch s.Freq {
+	case Yearly:
+		for d := period; d.Year() == period.Year(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case Monthly:
+		for d := period; d.Month() == period.Month(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case Weekly:
+		for i := 0; i < 7; i++ {
+			days = append(days, period.AddDate(0, 0, i))
+		}
+	default:
+		days = append(days, truncateDay(period))
+	}
+
+	hours := s.timeValues(s.ByHour, start.Hour(), period.Hour(), Hourly)
+	minutes := s.timeValues(s.ByMinute, start.Minute(), period.Minute(), Minutely)
+	seconds := s.timeValues(s.BySecond, start.Second(), period.Second(), Secondly)
+
+	var result []time.Time
+	for _, d := range days {
+		if !s.dayMatches(d) {
+			continue
+		}
+		for _, h := range hours {
+			for _, m := range minutes {
+				for _, sec := range seconds {
+					result = append(result, time.Date(d.Year(), d.Month(), d.Day(), h, m, sec, 0, time.UTC))
+				}
+			}
+		}
+	}
+
+	if len(s.BySetPos) == 0 {
+		return result
+	}
+	var selected []time.Time
+	for _, pos := range s.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(result) + pos
+		}
+		if i >= 0 && i < len(result) {
+			selected = append(selected, result[i])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	unique := selected[:0]
+	for i, t := range selected {
+		if i == 0 || !t.Equal(selected[i-1]) {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+// timeValues returns the sorted values of a time component (hour, minute or
+// second) of the occurrences of a period: the ones of the BYxxx part if the
+// frequency is coarser than the component, the one of the period if it matches
+// the BYxxx part otherwise, or the one of the start by default.
+func (s *RRuleSchedule) timeValues(by []int, startValue, periodValue int, component Frequency) []int {
+	if s.Freq <= component {
+		if len(by) == 0 || containsInt(by, periodValue) {
+			return []int{periodValue}
+		}
+		return nil
+	}
+	if len(by) == 0 {
+		return []int{startValue}
+	}
+	values := append([]int(nil), by...)
+	sort.Ints(values)
+	return values
+}
+
+// dayMatches returns true if the given day satisfies the day parts of the rule,
+// including the ones implied by the start of the rule.
+func (s *RRuleSchedule) dayMatches(d time.Time) bool {
+	byMonth, byMonthDay, byDay := s.ByMonth, s.ByMonthDay, s.ByDay
+	noDayPart := len(s.ByWeekNo) == 0 && len(s.ByYearDay) == 0 && len(byMonthDay) == 0 && len(byDay) == 0
+	switch {
+	case s.Freq == Yearly && noDayPart:
+		if len(byMonth) == 0 {
+			byMonth = []int{int(s.Start.Month())}
+		}
+		byMonthDay = []int{s.Start.Day()}
+	case s.Freq == Monthly && noDayPart:
+		byMonthDay = []int{s.Start.Day()}
+	case s.Freq == Weekly && noDayPart:
+		byDay = []RRuleWeekday{{Weekday: s.Start.Weekday()}}
+	}
+
+	if len(byMonth) > 0 && !containsInt(byMonth, int(d.Month())) {
+		return false
+	}
+	if len(s.ByWeekNo) > 0 {
+		week, weeks := weekNumber(d, s.Wkst)
+		if !containsInt(s.ByWeekNo, week) && !containsInt(s.ByWeekNo, week-weeks-1) {
+			return false
+		}
+	}
+	if len(s.ByYearDay) > 0 {
+		daysInYear := time.Date(d.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		if !containsInt(s.ByYearDay, d.YearDay()) && !containsInt(s.ByYearDay, d.YearDay()-daysInYear-1) {
+			return false
+		}
+	}
+	if len(byMonthDay) > 0 {
+		if !containsInt(byMonthDay, d.Day()) && !containsInt(byMonthDay, d.Day()-daysIn(d.Year(), d.Month())-1) {
+			return false
+		}
+	}
+	if len(byDay) > 0 {
+		match := false
+		for _, wd := range byDay {
+			if wd.Weekday == d.Weekday() && (wd.N == 0 || s.weekdayOccurrenceMatches(d, wd.N)) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+// weekdayOccurrenceMatches returns true if the given day is the n-th
+// occurrence of its day of week, within its month for monthly rules (and
+// yearly rules restricted to some months), within its year otherwise.
+func (s *RRuleSchedule) weekdayOccurrenceMatches(d time.Time, n int) bool {
+	if s.Freq == Monthly || s.Freq == Yearly && len(s.ByMonth) > 0 {
+		if n > 0 {
+			return (d.Day()-1)/7+1 == n
+		}
+		return -((daysIn(d.Year(), d.Month())-d.Day())/7 + 1) == n
+	}
+	if n > 0 {
+		return (d.YearDay()-1)/7+1 == n
+	}
+	daysInYear := time.Date(d.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	return -((daysInYear-d.YearDay())/7 + 1) == n
+}
+
+// weekNumber returns the week number of the given day and the number of weeks
+// of the year this week belongs to. As in ISO 8601, the first week of a year is
+// the first one with at least 4 days in this year, but weeks start on wkst.
+func weekNumber(d time.Time, wkst time.Weekday) (int, int) {
+	d = truncateDay(d)
+	year := d.Year()
+	first := firstWeekStart(year, wkst)
+	if d.Before(first) {
+		year--
+		first = firstWeekStart(year, wkst)
+	} else if next := firstWeekStart(year+1, wkst); !d.Before(next) {
+		year++
+		first = next
+	}
+	weeks := int(firstWeekStart(year+1, wkst).Sub(first).Hours()) / (24 * 7)
+	return int(d.Sub(first).Hours())/(24*7) + 1, weeks
+}
+
+// firstWeekStart returns the first day of the first week of the given year.
+func firstWeekStart(year int, wkst time.Weekday) time.Time {
+	return weekStart(time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC), wkst)
+}
+
+// weekStart returns the first day of the week of the given day.
+func weekStart(d time.Time, wkst time.Weekday) time.Time {
+	offset := (int(d.Weekday()) - int(wkst) + 7) % 7
+	return truncateDay(d).AddDate(0, 0, -offset)
+}
+
+// truncateDay returns the beginning of the day of the given time.
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the iCalendar content lines of the rule, separated by spaces,
+// e.g. "DTSTART:19700101T000000 RRULE:FREQ=DAILY;BYHOUR=9". Parsing it gives
+// back an equivalent rule, but with the Horizon of the parser: it is not
+// encoded.
+func (s *RRuleSchedule) String() string {
+	lines := []string{"DTSTART" + s.formatICalTime(s.Start, true)}
+
+	parts := []string{"FREQ=" + s.Freq.String()}
+	if s.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(s.Interval))
+	}
+	if s.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(s.Count))
+	}
+	if !s.Until.IsZero() {
+		parts = append(parts, "UNTIL="+s.formatICalTime(s.Until, false)[1:])
+	}
+	if s.Wkst != time.Monday {
+		parts = append(parts, "WKST="+rruleWeekdayNames[s.Wkst])
+	}
+	for _, by := range []struct {
+		name   string
+		values []int
+	}{
+		{"BYSECOND", s.BySecond},
+		{"BYMINUTE", s.ByMinute},
+		{"BYHOUR", s.ByHour},
+		{"BYMONTHDAY", s.ByMonthDay},
+		{"BYYEARDAY", s.ByYearDay},
+		{"BYWEEKNO", s.ByWeekNo},
+		{"BYMONTH", s.ByMonth},
+		{"BYSETPOS", s.BySetPos},
+	} {
+		if len(by.values) > 0 {
+			values := make([]string, len(by.values))
+			for i, v := range by.values {
+				values[i] = strconv.Itoa(v)
+			}
+			parts = append(parts, by.name+"="+strings.Join(values, ","))
+		}
+	}
+	if len(s.ByDay) > 0 {
+		days := make([]string, len(s.ByDay))
+		for i, d := range s.ByDay {
+			days[i] = rruleWeekdayNames[d.Weekday]
+			if d.N != 0 {
+				days[i] = strconv.Itoa(d.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	lines = append(lines, "RRULE:"+strings.Join(parts, ";"))
+
+	if len(s.Exdates) > 0 {
+		exdates := make([]string, len(s.Exdates))
+		for i, exdate := range s.Exdates {
+			value := s.formatICalTime(exdate, true)
+			exdates[i] = value[strings.Index(value, ":")+1:]
+		}
+		params := s.formatICalTime(s.Start, true)
+		lines = append(lines, "EXDATE"+params[:strings.Index(params, ":")+1]+strings.Join(exdates, ","))
+	}
+
+	return strings.Join(lines, " ")
+}
+
+// formatICalTime returns the given time of the rule as the value of a
+// property, with its parameters if withParams is true: ":20060102T150405" for a
+// floating time, ":20060102T150405Z" for a UTC time, and
+// ";TZID=Europe/Paris:20060102T150405" otherwise. Without parameters, the
+// times of other locations are given in UTC.
+func (s *RRuleSchedule) formatICalTime(t time.Time, withParams bool) string {
+	const layout = "20060102T150405"
+	switch {
+	case s.Location == nil:
+		return ":" + t.Format(layout)
+	case s.Location == time.UTC || !withParams:
+		return ":" + t.UTC().Format(layout) + "Z"
+	default:
+		return ";TZID=" + s.Location.String() + ":" + t.In(s.Location).Format(layout)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, using String.
+func (s *RRuleSchedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, using Parse: the Horizon
+// is the default one.
+func (s *RRuleSchedule) UnmarshalText(text []byte) error {
+	schedule, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	rule, ok := schedule.(*RRuleSchedule)
+	if !ok {
+		return unexpectedScheduleError(text, schedule)
+	}
+	*s = *rule
+	return nil
+}
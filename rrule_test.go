@@ -0,0 +1,238 @@
+package etcdcron
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRRuleNext(t *testing.T) {
+	// Examples of RFC 5545, section 3.8.5.3.
+	runs := []struct {
+		start, rule string
+		expected    []string
+	}{
+		{"19970902T090000", "FREQ=DAILY;COUNT=10", []string{
+			"1997-09-02 09:00", "1997-09-03 09:00", "1997-09-04 09:00", "1997-09-05 09:00", "1997-09-06 09:00",
+			"1997-09-07 09:00", "1997-09-08 09:00", "1997-09-09 09:00", "1997-09-10 09:00", "1997-09-11 09:00", "",
+		}},
+		{"19970902T090000", "FREQ=DAILY;INTERVAL=10;COUNT=5", []string{
+			"1997-09-02 09:00", "1997-09-12 09:00", "1997-09-22 09:00", "1997-10-02 09:00", "1997-10-12 09:00", "",
+		}},
+		{"19970902T090000", "FREQ=WEEKLY;COUNT=4", []string{
+			"1997-09-02 09:00", "1997-09-09 09:00", "1997-09-16 09:00", "1997-09-23 09:00", "",
+		}},
+		{"19970901T090000", "FREQ=WEEKLY;INTERVAL=2;UNTIL=19971224T000000Z;WKST=SU;BYDAY=MO,WE,FR", []string{
+			"1997-09-01 09:00", "1997-09-03 09:00", "1997-09-05 09:00", "1997-09-15 09:00", "1997-09-17 09:00",
+			"1997-09-19 09:00", "1997-09-29 09:00",
+		}},
+		{"19970905T090000", "FREQ=MONTHLY;COUNT=10;BYDAY=1FR", []string{
+			"1997-09-05 09:00", "1997-10-03 09:00", "1997-11-07 09:00", "1997-12-05 09:00", "1998-01-02 09:00",
+			"1998-02-06 09:00", "1998-03-06 09:00", "1998-04-03 09:00", "1998-05-01 09:00", "1998-06-05 09:00", "",
+		}},
+		{"19970922T090000", "FREQ=MONTHLY;COUNT=6;BYDAY=-2MO", []string{
+			"1997-09-22 09:00", "1997-10-20 09:00", "1997-11-17 09:00", "1997-12-22 09:00", "1998-01-19 09:00",
+			"1998-02-16 09:00", "",
+		}},
+		{"19970930T090000", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", []string{
+			"1997-09-30 09:00", "1997-10-31 09:00", "1997-11-28 09:00", "1997-12-31 09:00", "1998-01-30 09:00",
+			"1998-02-27 09:00", "1998-03-31 09:00",
+		}},
+		{"19970930T090000", "FREQ=MONTHLY;COUNT=6;BYMONTHDAY=1,-1", []string{
+			"1997-09-30 09:00", "1997-10-01 09:00", "1997-10-31 09:00", "1997-11-01 09:00", "1997-11-30 09:00",
+			"1997-12-01 09:00", "",
+		}},
+		{"19970902T090000", "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", []string{
+			"1998-02-13 09:00", "1998-03-13 09:00", "1998-11-13 09:00", "1999-08-13 09:00", "2000-10-13 09:00",
+		}},
+		{"19970610T090000", "FREQ=YEARLY;COUNT=4;BYMONTH=6,7", []string{
+			"1997-06-10 09:00", "1997-07-10 09:00", "1998-06-10 09:00", "1998-07-10 09:00", "",
+		}},
+		{"19970519T090000", "FREQ=YEARLY;BYDAY=20MO", []string{
+			"1997-05-19 09:00", "1998-05-18 09:00", "1999-05-17 09:00",
+		}},
+		{"19970512T090000", "FREQ=YEARLY;BYWEEKNO=20;BYDAY=MO", []string{
+			"1997-05-12 09:00", "1998-05-11 09:00", "1999-05-17 09:00",
+		}},
+		{"19970313T090000", "FREQ=YEARLY;BYMONTH=3;BYDAY=TH", []string{
+			"1997-03-13 09:00", "1997-03-20 09:00", "1997-03-27 09:00", "1998-03-05 09:00",
+		}},
+		{"19961105T090000", "FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8", []string{
+			"1996-11-05 09:00", "2000-11-07 09:00", "2004-11-02 09:00",
+		}},
+		{"19970902T090000", "FREQ=HOURLY;INTERVAL=3;UNTIL=19970902T210000Z", []string{
+			"1997-09-02 09:00", "1997-09-02 12:00", "1997-09-02 15:00", "",
+		}},
+		{"19970902T090000", "FREQ=MINUTELY;INTERVAL=15;COUNT=6", []string{
+			"1997-09-02 09:00", "1997-09-02 09:15", "1997-09-02 09:30", "1997-09-02 09:45", "1997-09-02 10:00",
+			"1997-09-02 10:15", "",
+		}},
+		{"19970902T090000", "FREQ=DAILY;BYHOUR=9,10,11,12,13,14,15,16;BYMINUTE=0,20,40", []string{
+			"1997-09-02 09:00", "1997-09-02 09:20", "1997-09-02 09:40", "1997-09-02 10:00",
+		}},
+
+		// Last weekday of each quarter.
+		{"20260301T090000", "FREQ=MONTHLY;INTERVAL=3;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", []string{
+			"2026-03-31 09:00", "2026-06-30 09:00", "2026-09-30 09:00", "2026-12-31 09:00",
+		}},
+		// Every 2 weeks on Monday and Thursday until 2027-06-30.
+		{"20270601T090000", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20270630T235959", []string{
+			"2027-06-03 09:00", "2027-06-14 09:00", "2027-06-17 09:00", "2027-06-28 09:00", "",
+		}},
+		// Impossible rule.
+		{"20260101T090000", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", []string{""}},
+	}
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range runs {
+		spec := "DTSTART;TZID=America/New_York:" + c.start + " RRULE:" + c.rule
+		s, err := ParseRRule(spec)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		next := s.Start.Add(-time.Second)
+		for i, expected := range c.expected {
+			next = s.Next(next)
+			actual := ""
+			if !next.IsZero() {
+				actual = next.In(loc).Format("2006-01-02 15:04")
+			}
+			if actual != expected {
+				t.Errorf("%s: occurrence %d: (expected) %q != %q (actual)", spec, i, expected, actual)
+				break
+			}
+			if next.IsZero() {
+				break
+			}
+		}
+	}
+}
+
+func TestRRuleFromTime(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runs := []struct {
+		time     time.Time
+		spec     string
+		expected time.Time
+	}{
+		// Floating rules follow the location of the given time.
+		{time.Date(2026, 7, 9, 10, 0, 0, 0, time.UTC), "RRULE:FREQ=DAILY;BYHOUR=9", time.Date(2026, 7, 10, 9, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 7, 9, 10, 0, 0, 0, paris), "RRULE:FREQ=DAILY;BYHOUR=9", time.Date(2026, 7, 10, 9, 0, 0, 0, paris)},
+		{time.Date(2026, 7, 9, 10, 0, 0, 0, time.UTC), "RRULE:FREQ=SECONDLY;INTERVAL=7", time.Date(2026, 7, 9, 10, 0, 1, 0, time.UTC)},
+		{time.Date(2026, 7, 9, 10, 0, 0, 0, time.UTC), "RRULE:FREQ=MINUTELY;BYMONTH=8", time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)},
+
+		// Fixed rules don't.
+		{time.Date(2026, 7, 9, 10, 0, 0, 0, time.UTC), "DTSTART;TZID=Europe/Paris:20260101T090000 RRULE:FREQ=DAILY", time.Date(2026, 7, 10, 7, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 7, 9, 10, 0, 0, 0, time.UTC), "DTSTART:20260101T090000Z RRULE:FREQ=DAILY", time.Date(2026, 7, 10, 9, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 7, 9, 10, 0, 0, 0, time.UTC), "CRON_TZ=Europe/Paris RRULE:FREQ=DAILY;BYHOUR=9", time.Date(2026, 7, 10, 7, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), "CRON_TZ=Europe/Paris DTSTART:20260105T090000 RRULE:FREQ=DAILY", time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 1, 6, 8, 0, 0, 0, time.UTC), "CRON_TZ=Europe/Paris DTSTART:20260105T090000 RRULE:FREQ=DAILY EXDATE:20260107T090000", time.Date(2026, 1, 8, 8, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 1, 10, 8, 0, 0, 0, time.UTC), "CRON_TZ=Europe/Paris DTSTART:20260105T090000 RRULE:FREQ=DAILY;UNTIL=20260110T090000", time.Time{}},
+
+		// Excluded dates, in any time zone.
+		{time.Date(2026, 7, 9, 10, 0, 0, 0, time.UTC), "DTSTART:20260101T090000Z RRULE:FREQ=DAILY EXDATE:20260710T090000Z,20260711T090000Z", time.Date(2026, 7, 12, 9, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 7, 9, 10, 0, 0, 0, time.UTC), "DTSTART;TZID=Europe/Paris:20260101T090000 RRULE:FREQ=DAILY EXDATE:20260710T070000Z", time.Date(2026, 7, 11, 7, 0, 0, 0, time.UTC)},
+
+		// Occurrences before the start are ignored.
+		{time.Date(2026, 7, 9, 10, 0, 0, 0, time.UTC), "DTSTART:20270101T000000Z RRULE:FREQ=DAILY", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range runs {
+		s, err := Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		actual := s.Next(c.time)
+		if !actual.Equal(c.expected) {
+			t.Errorf("%s, %s: (expected) %v != %v (actual)", c.spec, c.time, c.expected, actual)
+		}
+	}
+}
+
+func TestRRuleCount(t *testing.T) {
+	// Every rule is compared to the same rule selecting all the occurrences of
+	// each period, whose occurrences are counted one by one from the start.
+	specs := []string{
+		"DTSTART:19970902T090000 RRULE:FREQ=DAILY;COUNT=1000",
+		"DTSTART:19970902T090000 RRULE:FREQ=DAILY;INTERVAL=3;COUNT=1000;BYHOUR=9,17;BYMINUTE=0,30",
+		"DTSTART:19970902T090000 RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=500;BYDAY=MO,TH,MO",
+		"DTSTART:19970131T090000 RRULE:FREQ=MONTHLY;COUNT=100",
+		"DTSTART:19970115T090000 RRULE:FREQ=MONTHLY;COUNT=100",
+		"DTSTART:19960229T090000 RRULE:FREQ=YEARLY;COUNT=10",
+		"DTSTART:19970902T090000 RRULE:FREQ=HOURLY;INTERVAL=5;COUNT=10000;BYMINUTE=15,45",
+		"DTSTART:19970902T090000 RRULE:FREQ=MINUTELY;COUNT=100000;BYHOUR=9",
+		"DTSTART:19970902T090007 RRULE:FREQ=SECONDLY;INTERVAL=7;COUNT=200000 EXDATE:19970903T090007",
+	}
+	from := time.Date(1997, 9, 2, 0, 0, 0, 0, time.UTC)
+	for _, spec := range specs {
+		rule, err := ParseRRule(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counted, err := ParseRRule(strings.Replace(spec, "COUNT=", "BYSETPOS=1,2,3,4,5,6,7,8;COUNT=", 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range []time.Duration{0, 37 * time.Hour, 1000 * time.Hour, 10000 * time.Hour, 100000 * time.Hour} {
+			if expected, actual := counted.Next(from.Add(d)), rule.Next(from.Add(d)); !actual.Equal(expected) {
+				t.Errorf("%s, %v: (expected) %v != %v (actual)", spec, from.Add(d), expected, actual)
+			}
+		}
+	}
+
+	// The occurrences of the long rules aren't counted one by one.
+	long, err := ParseRRule("DTSTART:19700101T000000Z RRULE:FREQ=MINUTELY;COUNT=10000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := long.Next(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)); !next.IsZero() {
+		t.Errorf("(expected) no occurrence != %v (actual)", next)
+	}
+	if next, expected := long.Next(time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)), time.Date(1980, 1, 1, 0, 1, 0, 0, time.UTC); !next.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, next)
+	}
+}
+
+func TestParseRRuleErrors(t *testing.T) {
+	errorCases := []struct {
+		spec  string
+		kind  error
+		field string
+	}{
+		{"RRULE:", ErrEmptySpec, ""},
+		{"RRULE:INTERVAL=2", ErrInvalidValue, ""},
+		{"RRULE:FREQ=FORTNIGHTLY", ErrInvalidValue, "FREQ"},
+		{"RRULE:FREQ=DAILY;INTERVAL=0", ErrOutOfRange, "INTERVAL"},
+		{"RRULE:FREQ=DAILY;BYHOUR=24", ErrOutOfRange, "BYHOUR"},
+		{"RRULE:FREQ=DAILY;BYMONTHDAY=-32", ErrOutOfRange, "BYMONTHDAY"},
+		{"RRULE:FREQ=MONTHLY;BYDAY=1XX", ErrInvalidValue, "BYDAY"},
+		{"RRULE:FREQ=DAILY;COUNT=2;UNTIL=20260101", ErrInvalidValue, ""},
+		{"RRULE:FREQ=DAILY;FOO=1", ErrInvalidValue, "FOO"},
+		{"DTSTART:2026", ErrInvalidValue, "DTSTART"},
+		{"DTSTART;TZID=Mars/Olympus:20260101T000000 RRULE:FREQ=DAILY", ErrUnknownLocation, "DTSTART"},
+		{"DTSTART:20260101T000000", ErrEmptySpec, ""},
+		{"RRULE:FREQ=DAILY;COUNT=2", ErrInvalidValue, "COUNT"},
+	}
+
+	for _, c := range errorCases {
+		_, err := Parse(c.spec)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%s: expected a *ParseError, got %v", c.spec, err)
+			continue
+		}
+		if !errors.Is(err, c.kind) || parseErr.Field != c.field || parseErr.Spec != c.spec {
+			t.Errorf("%s: unexpected error %#v", c.spec, parseErr)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package etcdcron
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SolarEvent is a daily event of the sun at a given place.
+type SolarEvent int
+
+const (
+	// Sunrise is the time the upper limb of the sun appears on the horizon.
+	Sunrise SolarEvent = iota
+	// Sunset is the time the upper limb of the sun disappears below the
+	// horizon.
+	Sunset
+)
+
+func (e SolarEvent) String() string {
+	switch e {
+	case Sunrise:
+		return "sunrise"
+	case Sunset:
+		return "sunset"
+	}
+	return "SolarEvent(" + strconv.Itoa(int(e)) + ")"
+}
+
+// maxSolarDays is the number of days searched for a solar event, longer than
+// the polar nights and days.
+const maxSolarDays = 400
+
+// SolarSchedule is activated every day at the sunrise or the sunset at a given
+// place, moved by an offset, e.g. "30 minutes after sunset". The times are
+// computed offline with the NOAA solar calculation algorithm, accurate to about
+// a minute between the polar circles, and truncated to the minute.
+type SolarSchedule struct {
+	Event SolarEvent
+	// Latitude and Longitude of the place, in degrees, positive to the north
+	// and to the east.
+	Latitude, Longitude float64
+	// Offset is added to the time of the event, negative for a time before it.
+	Offset time.Duration
+}
+
+// Solar returns a Schedule activated at the given event at the given place,
+// moved by the offset. The days when the sun doesn't rise or set, beyond the
+// polar circles, are skipped. At the poles, the sun never rises nor sets.
+func Solar(event SolarEvent, latitude, longitude float64, offset time.Duration) *SolarSchedule {
+	return &SolarSchedule{Event: event, Latitude: latitude, Longitude: longitude, Offset: offset}
+}
+
+// Next returns the activation time later than the given time, in its location,
+// or the zero time if the event doesn't happen within maxSolarDays.
+func (s *SolarSchedule) Next(t time.Time) time.Time {
+	// The event of a UTC day may happen on the previous or the next day,
+	// depending on the longitude.
+	day := floorDiv(int(t.Add(-s.Offset).Unix()), 24*60*60) - 2
+	for end := day + maxSolarDays; day < end; day++ {
+		event, ok := s.event(day)
+		if !ok {
+			continue
+		}
+		if activation := event.Add(s.Offset); activation.After(t) {
+			return activation.In(t.Location())
+		}
+	}
+	return time.Time{}
+}
+
+// event returns the time of the event on the given day from January 1st 1970,
+// truncated to the minute, or false if it doesn't happen on that day.
+func (s *SolarSchedule) event(day int) (time.Time, bool) {
+	// Compute the position of the sun at the solar noon, then again at the
+	// time of the event.
+	minutes := 720 - 4*s.Longitude
+	for i := 0; i < 2; i++ {
+		var ok bool
+		if minutes, ok = s.eventMinutes(day, minutes); !ok {
+			return time.Time{}, false
+		}
+	}
+	// The floating-point results may differ in their last bits between
+	// architectures, e.g. with fused multiply-adds: only the minutes are kept.
+	return time.Unix((int64(day)*24*60+int64(math.Floor(minutes)))*60, 0).UTC(), true
+}
+
+// eventMinutes returns the time of the event on the given day, in minutes from
+// its midnight UTC, with the position of the sun at the given time of the day.
+// It returns false if the sun doesn't cross the horizon on that day.
+func (s *SolarSchedule) eventMinutes(day int, minutes float64) (float64, bool) {
+	const rad = math.Pi / 180
+
+	// Julian centuries since J2000.0.
+	julianDay := float64(day) + 2440587.5 + minutes/1440
+	c := (julianDay - 2451545) / 36525
+
+	meanLongitude := math.Mod(280.46646+c*(36000.76983+c*0.0003032), 360)
+	meanAnomaly := 357.52911 + c*(35999.05029-0.0001537*c)
+	eccentricity := 0.016708634 - c*(0.000042037+0.0000001267*c)
+	center := math.Sin(meanAnomaly*rad)*(1.914602-c*(0.004817+0.000014*c)) +
+		math.Sin(2*meanAnomaly*rad)*(0.019993-0.000101*c) +
+		math.Sin(3*meanAnomaly*rad)*0.000289
+	omega := 125.04 - 1934.136*c
+	apparentLongitude := meanLongitude + center - 0.00569 - 0.00478*math.Sin(omega*rad)
+	meanObliquity := 23 + (26+(21.448-c*(46.815+c*(0.00059-c*0.001813)))/60)/60
+	obliquity := meanObliquity + 0.00256*math.Cos(omega*rad)
+	declination := math.Asin(math.Sin(obliquity*rad) * math.Sin(apparentLongitude*rad))
+
+	y := math.Pow(math.Tan(obliquity*rad/2), 2)
+	equationOfTime := 4 / rad * (y*math.Sin(2*meanLongitude*rad) -
+		2*eccentricity*math.Sin(meanAnomaly*rad) +
+		4*eccentricity*y*math.Sin(meanAnomaly*rad)*math.Cos(2*meanLongitude*rad) -
+		0.5*y*y*math.Sin(4*meanLongitude*rad) -
+		1.25*eccentricity*eccentricity*math.Sin(2*meanAnomaly*rad))
+
+	// The hour angle of the sun when its upper limb is on the horizon, with
+	// the atmospheric refraction.
+	latitude := s.Latitude * rad
+	cosHourAngle := math.Cos(90.833*rad)/(math.Cos(latitude)*math.Cos(declination)) - math.Tan(latitude)*math.Tan(declination)
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return 0, false
+	}
+	hourAngle := math.Acos(cosHourAngle) / rad
+
+	noon := 720 - 4*s.Longitude - equationOfTime
+	if s.Event == Sunrise {
+		return noon - 4*hourAngle, true
+	}
+	return noon + 4*hourAngle, true
+}
+
+// String returns the descriptor of the schedule, e.g. "@sunset 48.8566 2.3522
+// 30m0s".
+func (s *SolarSchedule) String() string {
+	spec := "@" + s.Event.String() + " " + strconv.FormatFloat(s.Latitude, 'f', -1, 64) + " " + strconv.FormatFloat(s.Longitude, 'f', -1, 64)
+	if s.Offset != 0 {
+		spec += " " + s.Offset.String()
+	}
+	return spec
+}
+
+// MarshalText implements encoding.TextMarshaler, using String.
+func (s *SolarSchedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, using Parse.
+func (s *SolarSchedule) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	solar, ok := parsed.(*SolarSchedule)
+	if !ok {
+		return unexpectedScheduleError(text, parsed)
+	}
+	*s = *solar
+	return nil
+}
+
+// parseSolar parses the arguments of a "@sunrise" or "@sunset" descriptor: a
+// latitude and a longitude in decimal degrees, optionally followed by an
+// offset, e.g. "30m" or "-1h".
+func parseSolar(event SolarEvent, args string) (*SolarSchedule, *ParseError) {
+	fields := strings.Fields(args)
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, parseErrorf(ErrInvalidValue, args, "expected a latitude, a longitude and an optional offset")
+	}
+
+	var coordinates [2]float64
+	for i, limit := range []float64{90, 180} {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil || math.IsNaN(value) {
+			return nil, parseErrorf(ErrInvalidValue, fields[i], "expected a number of degrees")
+		}
+		if value < -limit || value > limit {
+			return nil, parseErrorf(ErrOutOfRange, fields[i], "not between -%v and %v", limit, limit)
+		}
+		coordinates[i] = value
+	}
+
+	var offset time.Duration
+	if len(fields) == 3 {
+		var err error
+		if offset, err = time.ParseDuration(fields[2]); err != nil {
+			return nil, parseErrorf(ErrBadDuration, fields[2], "%v", err)
+		}
+	}
+	return Solar(event, coordinates[0], coordinates[1], offset), nil
+}
@@ -0,0 +1,80 @@
+package etcdcron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSolarScheduleNext(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		schedule *SolarSchedule
+		time     string
+		expected string
+	}{
+		// Paris, at the solstices.
+		{Solar(Sunrise, 48.8566, 2.3522, 0), "2026-06-21T00:00:00Z", "2026-06-21T03:47:00Z"},
+		{Solar(Sunset, 48.8566, 2.3522, 0), "2026-06-21T00:00:00Z", "2026-06-21T19:58:00Z"},
+		{Solar(Sunrise, 48.8566, 2.3522, 0), "2026-12-21T00:00:00Z", "2026-12-21T07:41:00Z"},
+		{Solar(Sunset, 48.8566, 2.3522, 0), "2026-12-21T00:00:00Z", "2026-12-21T15:56:00Z"},
+
+		// The next day, once the event is past.
+		{Solar(Sunset, 48.8566, 2.3522, 0), "2026-06-21T20:00:00Z", "2026-06-22T19:58:00Z"},
+
+		// With an offset, before or after the event.
+		{Solar(Sunset, 48.8566, 2.3522, 30*time.Minute), "2026-06-21T20:00:00Z", "2026-06-21T20:28:00Z"},
+		{Solar(Sunrise, 48.8566, 2.3522, -time.Hour), "2026-06-21T00:00:00Z", "2026-06-21T02:47:00Z"},
+
+		// West and south, where the events are on another UTC day.
+		{Solar(Sunset, 40.7128, -74.0060, 0), "2026-03-20T00:00:00Z", "2026-03-20T23:08:00Z"},
+		{Solar(Sunrise, -33.8688, 151.2093, 0), "2026-12-20T12:00:00Z", "2026-12-20T18:41:00Z"},
+		{Solar(Sunset, -14.27, -170.70, 0), "2026-06-21T00:00:00Z", "2026-06-21T05:03:00Z"},
+
+		// Tromsø: no sunrise during the polar night, no sunset during the
+		// midnight sun.
+		{Solar(Sunrise, 69.6492, 18.9553, 0), "2026-12-01T00:00:00Z", "2027-01-15T10:35:00Z"},
+		{Solar(Sunset, 69.6492, 18.9553, 0), "2026-06-01T00:00:00Z", "2026-07-26T22:13:00Z"},
+	}
+
+	for _, c := range tests {
+		from, _ := time.Parse(time.RFC3339, c.time)
+		expected, _ := time.Parse(time.RFC3339, c.expected)
+		actual := c.schedule.Next(from)
+		if diff := actual.Sub(expected); diff < -time.Minute || diff > time.Minute {
+			t.Errorf("%v, %s => (expected) %v != %v (actual)", c.schedule, c.time, expected, actual)
+		}
+		if actual.Second() != 0 || actual.Nanosecond() != 0 {
+			t.Errorf("%v, %s => %v is not truncated to the minute", c.schedule, c.time, actual)
+		}
+	}
+
+	// The activation time is in the location of the given time.
+	schedule := Solar(Sunset, 48.8566, 2.3522, 0)
+	if next := schedule.Next(time.Date(2026, 6, 21, 0, 0, 0, 0, paris)); next.Location() != paris {
+		t.Errorf("(expected) %v != %v (actual)", paris, next.Location())
+	}
+
+	// The sun doesn't rise near the North Pole from October to March.
+	schedule = Solar(Sunrise, 89, 0, 0)
+	if next := schedule.Next(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)); next.Month() != time.March {
+		t.Errorf("(expected) a sunrise in March != %v (actual)", next)
+	}
+}
+
+func TestSolarScheduleParse(t *testing.T) {
+	schedule := mustParse(t, "@sunset 48.8566 2.3522 30m")
+	expected := Solar(Sunset, 48.8566, 2.3522, 30*time.Minute)
+	if solar, ok := schedule.(*SolarSchedule); !ok || *solar != *expected {
+		t.Errorf("(expected) %+v != %+v (actual)", expected, schedule)
+	}
+
+	schedule = mustParse(t, "@sunrise -33.8688 151.2093")
+	expected = Solar(Sunrise, -33.8688, 151.2093, 0)
+	if solar, ok := schedule.(*SolarSchedule); !ok || *solar != *expected {
+		t.Errorf("(expected) %+v != %+v (actual)", expected, schedule)
+	}
+}
@@ -1,6 +1,10 @@
 package etcdcron
 
 import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -8,6 +12,34 @@ import (
 // traditional crontab specification. It is computed initially and stored as bit sets.
 type SpecSchedule struct {
 	Second, Minute, Hour, Dom, Month, Dow uint64
+
+	// Quartz-style modifiers of the day-of-month and day-of-week fields, also
+	// stored as bit sets:
+	//   - DomLast: bit n is set for "L-n", the n-th day before the last day of
+	//     the month ("L" is "L-0")
+	//   - DomWeekday: bit n is set for "nW", the weekday nearest to the n-th
+	//     day of the month, bit 0 is set for "LW", the last weekday of the month
+	//   - DowLast: bit d is set for "dL", the last day d of the month
+	//   - DowNth: bit (n-1)*7+d is set for "d#n", the n-th day d of the month
+	DomLast, DomWeekday, DowLast, DowNth uint64
+
+	// Years restricts the schedule to the given years, in ascending order. If
+	// nil, the schedule is active every year.
+	Years []int
+
+	// Horizon is the number of years Next looks ahead (and Prev looks back) for
+	// an activation time before giving up. If zero, DefaultHorizon is used.
+	// Schedules restricted to some years are always searched up to the last
+	// (or back to the first) of them.
+	Horizon int
+
+	// Location overrides the time zone in which the schedule is evaluated. If
+	// nil, the location of the time given to Next is used.
+	Location *time.Location
+
+	// DST tells how the daylight saving time transitions of the time zone are
+	// handled, see DSTPolicy.
+	DST DSTPolicy
 }
 
 // bounds provides a range of acceptable values (plus a map of name to value).
@@ -45,117 +77,563 @@ var (
 		"fri": 5,
 		"sat": 6,
 	}}
+	years = bounds{1970, 2099, nil}
 )
 
 const (
 	// Set the top bit if a star was included in the expression.
 	starBit = 1 << 63
+
+	// DefaultHorizon is the number of years SpecSchedule.Next looks ahead by
+	// default.
+	DefaultHorizon = 5
 )
 
 // Next returns the next time this schedule is activated, greater than the given
 // time.  If no time can be found to satisfy the schedule, return the zero time.
 func (s *SpecSchedule) Next(t time.Time) time.Time {
-	// General approach:
-	// For Month, Day, Hour, Minute, Second:
-	// Check if the time value matches.  If yes, continue to the next field.
-	// If the field doesn't match the schedule, then increment the field until it matches.
-	// While incrementing the field, a wrap-around brings it back to the beginning
-	// of the field list (since it is necessary to re-verify previous field
-	// values)
-
-	// Start at the earliest possible time (the upcoming second).
+	// Convert the given time into the schedule's time zone, if any. The time
+	// returned is converted back into the original location.
+	origLocation := t.Location()
+	if s.Location != nil {
+		t = t.In(s.Location)
+	}
+
+	// If no time is found within the horizon, return zero.
+	yearLimit := t.Year() + s.horizon()
+	if len(s.Years) > 0 {
+		yearLimit = s.Years[len(s.Years)-1]
+	}
+
+	// The activation times are found on the wall clock, and then placed on
+	// the time line according to the DST policy. In the first occurrence of
+	// a repeated period, the activation times of the second occurrence are
+	// later than t, but their wall clock is earlier.
+	wall := wallClock(t).Add(time.Duration(t.Nanosecond()))
+	from, to, repeated := repeatedWalls(t)
+	if repeated {
+		wall = from.Add(-time.Nanosecond)
+	}
+	var next time.Time
+	for {
+		wall = s.nextWall(wall, yearLimit)
+		if wall.IsZero() {
+			break
+		}
+		for _, instant := range s.instants(wall, t.Location()) {
+			if instant.After(t) && (next.IsZero() || instant.Before(next)) {
+				next = instant
+			}
+		}
+		// Within the repeated period, a later wall clock time may be an
+		// earlier instant.
+		if !next.IsZero() && (!repeated || !wall.Before(to)) {
+			break
+		}
+	}
+	if next.IsZero() {
+		return time.Time{}
+	}
+	return next.In(origLocation)
+}
+
+// nextWall returns the next wall clock time, expressed in UTC, matching the
+// schedule and later than the given one, or the zero time if there is none up
+// to the year limit.
+func (s *SpecSchedule) nextWall(t time.Time, yearLimit int) time.Time {
+	// Each field is set to the next value of its bit set, starting from the
+	// upcoming second. When a field has no value left, the field above it is
+	// incremented and the ones below it are reset, and the search starts
+	// over from the year.
 	t = t.Add(1*time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+
+	for year <= yearLimit {
+		if y := s.nextYear(year); y != year {
+			if y == 0 {
+				return time.Time{}
+			}
+			year, month, day, hour, minute, second = y, time.January, 1, 0, 0, 0
+		}
+
+		m, ok := nextBit(s.Month, int(month))
+		if !ok {
+			year, month, day, hour, minute, second = year+1, time.January, 1, 0, 0, 0
+			continue
+		}
+		if time.Month(m) != month {
+			month, day, hour, minute, second = time.Month(m), 1, 0, 0, 0
+		}
 
-	// This flag indicates whether a field has been incremented.
-	added := false
+		d, ok := nextBit(s.dayBits(year, month), day)
+		if !ok {
+			month, day, hour, minute, second = month+1, 1, 0, 0, 0
+			if month > time.December {
+				year, month = year+1, time.January
+			}
+			continue
+		}
+		if d != day {
+			day, hour, minute, second = d, 0, 0, 0
+		}
+
+		h, ok := nextBit(s.Hour, hour)
+		if !ok {
+			day, hour, minute, second = day+1, 0, 0, 0
+			continue
+		}
+		if h != hour {
+			hour, minute, second = h, 0, 0
+		}
 
-	// If no time is found within five years, return zero.
-	yearLimit := t.Year() + 5
+		mi, ok := nextBit(s.Minute, minute)
+		if !ok {
+			hour, minute, second = hour+1, 0, 0
+			continue
+		}
+		if mi != minute {
+			minute, second = mi, 0
+		}
 
-WRAP:
-	if t.Year() > yearLimit {
+		sec, ok := nextBit(s.Second, second)
+		if !ok {
+			minute, second = minute+1, 0
+			continue
+		}
+		return time.Date(year, month, day, hour, minute, sec, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// Prev returns the latest time this schedule is activated, earlier than the
+// given time. If no time can be found within the horizon before the given
+// time, the zero time is returned.
+func (s *SpecSchedule) Prev(t time.Time) time.Time {
+	origLocation := t.Location()
+	if s.Location != nil {
+		t = t.In(s.Location)
+	}
+
+	// If no time is found within the horizon, return zero.
+	yearLimit := t.Year() - s.horizon()
+	if len(s.Years) > 0 {
+		yearLimit = s.Years[0]
+	}
+
+	// In the second occurrence of a repeated period, the activation times of
+	// the first occurrence are earlier than t, but their wall clock is later.
+	wall := wallClock(t).Add(time.Duration(t.Nanosecond()))
+	from, to, repeated := repeatedWalls(t)
+	if repeated {
+		wall = to
+	}
+	var prev time.Time
+	for {
+		wall = s.prevWall(wall, yearLimit)
+		if wall.IsZero() {
+			break
+		}
+		for _, instant := range s.instants(wall, t.Location()) {
+			if instant.Before(t) && instant.After(prev) {
+				prev = instant
+			}
+		}
+		if !prev.IsZero() && (!repeated || wall.Before(from)) {
+			break
+		}
+	}
+	if prev.IsZero() {
 		return time.Time{}
 	}
+	return prev.In(origLocation)
+}
+
+// prevWall returns the previous wall clock time, expressed in UTC, matching
+// the schedule and earlier than the given one, or the zero time if there is
+// none down to the year limit.
+func (s *SpecSchedule) prevWall(t time.Time, yearLimit int) time.Time {
+	// Same approach as nextWall, the other way around: when a field has no
+	// value left, the field above it is decremented and the ones below it are
+	// set to their last value.
+
+	// Start at the latest possible time (the previous second).
+	if t.Nanosecond() > 0 {
+		t = t.Add(-time.Duration(t.Nanosecond()) * time.Nanosecond)
+	} else {
+		t = t.Add(-1 * time.Second)
+	}
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
 
-	// Find the first applicable month.
-	// If it's this month, then do nothing.
-	for 1<<uint(t.Month())&s.Month == 0 {
-		// If we have to add a month, reset the other parts to 0.
-		if !added {
-			added = true
-			// Otherwise, set the date at the beginning (since the current time is irrelevant).
-			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	for year >= yearLimit {
+		if y := s.prevYear(year); y != year {
+			if y == 0 {
+				return time.Time{}
+			}
+			year, month, day, hour, minute, second = y, time.December, 31, 23, 59, 59
 		}
-		t = t.AddDate(0, 1, 0)
 
-		// Wrapped around.
-		if t.Month() == time.January {
-			goto WRAP
+		m, ok := prevBit(s.Month, int(month))
+		if !ok {
+			year, month, day, hour, minute, second = year-1, time.December, 31, 23, 59, 59
+			continue
 		}
+		if time.Month(m) != month {
+			month, day, hour, minute, second = time.Month(m), 31, 23, 59, 59
+		}
+
+		d, ok := prevBit(s.dayBits(year, month), day)
+		if !ok {
+			month, day, hour, minute, second = month-1, 31, 23, 59, 59
+			if month < time.January {
+				year, month = year-1, time.December
+			}
+			continue
+		}
+		if d != day {
+			day, hour, minute, second = d, 23, 59, 59
+		}
+
+		h, ok := prevBit(s.Hour, hour)
+		if !ok {
+			day, hour, minute, second = day-1, 23, 59, 59
+			continue
+		}
+		if h != hour {
+			hour, minute, second = h, 59, 59
+		}
+
+		mi, ok := prevBit(s.Minute, minute)
+		if !ok {
+			hour, minute, second = hour-1, 59, 59
+			continue
+		}
+		if mi != minute {
+			minute, second = mi, 59
+		}
+
+		sec, ok := prevBit(s.Second, second)
+		if !ok {
+			minute, second = minute-1, 59
+			continue
+		}
+		return time.Date(year, month, day, hour, minute, sec, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// nextBit returns the lowest value of the bit set not lower than the given
+// one, ignoring the star bit.
+func nextBit(set uint64, from int) (int, bool) {
+	if from < 0 {
+		from = 0
 	}
+	if from >= 63 {
+		return 0, false
+	}
+	set &^= starBit
+	set &= ^uint64(0) << uint(from)
+	if set == 0 {
+		return 0, false
+	}
+	return bits.TrailingZeros64(set), true
+}
+
+// prevBit returns the highest value of the bit set not greater than the given
+// one, ignoring the star bit.
+func prevBit(set uint64, from int) (int, bool) {
+	if from < 0 {
+		return 0, false
+	}
+	set &^= starBit
+	if from < 63 {
+		set &= 1<<uint(from+1) - 1
+	}
+	if set == 0 {
+		return 0, false
+	}
+	return 63 - bits.LeadingZeros64(set), true
+}
 
-	// Now get a day in that month.
-	for !dayMatches(s, t) {
-		if !added {
-			added = true
-			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+// horizon returns the number of years Next and Prev look ahead and back.
+func (s *SpecSchedule) horizon() int {
+	if s.Horizon > 0 {
+		return s.Horizon
+	}
+	return DefaultHorizon
+}
+
+// nextYear returns the first year of the schedule not before the given one, or
+// 0 if there is none.
+func (s *SpecSchedule) nextYear(year int) int {
+	if s.Years == nil {
+		return year
+	}
+	for _, y := range s.Years {
+		if y >= year {
+			return y
 		}
-		t = t.AddDate(0, 0, 1)
+	}
+	return 0
+}
 
-		if t.Day() == 1 {
-			goto WRAP
+// prevYear returns the last year of the schedule not after the given one, or 0
+// if there is none.
+func (s *SpecSchedule) prevYear(year int) int {
+	if s.Years == nil {
+		return year
+	}
+	for i := len(s.Years) - 1; i >= 0; i-- {
+		if s.Years[i] <= year {
+			return s.Years[i]
 		}
 	}
+	return 0
+}
+
+// dayBits returns the bit set of the days of the given month satisfying the
+// schedule's day-of-week and day-of-month restrictions, as dayMatches.
+func (s *SpecSchedule) dayBits(year int, month time.Month) uint64 {
+	last := daysIn(year, month)
+	// Only the days 1 to last.
+	monthDays := uint64(1)<<uint(last+1) - 2
+	if weekdays := getBits(dow.min, dow.max, 1); s.Dom&monthDays == monthDays && s.Dow&weekdays == weekdays {
+		return monthDays
+	}
+	first := int(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday())
 
-	for 1<<uint(t.Hour())&s.Hour == 0 {
-		if !added {
-			added = true
-			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	domBits := s.Dom &^ starBit
+	if s.DomLast != 0 || s.DomWeekday != 0 {
+		for n := 0; n < last; n++ {
+			if 1<<uint(n)&s.DomLast > 0 {
+				domBits |= 1 << uint(last-n)
+			}
+		}
+		if s.DomWeekday&1 > 0 {
+			domBits |= 1 << uint(nearestWeekday(year, month, last))
+		}
+		for n := 1; n <= last; n++ {
+			if 1<<uint(n)&s.DomWeekday > 0 {
+				domBits |= 1 << uint(nearestWeekday(year, month, n))
+			}
 		}
-		t = t.Add(1 * time.Hour)
+	}
 
-		if t.Hour() == 0 {
-			goto WRAP
+	var dowBits uint64
+	for weekday := 0; weekday < 7; weekday++ {
+		// The first day of the month which is this day of the week.
+		day := 1 + (weekday-first+7)%7
+		if 1<<uint(weekday)&s.Dow > 0 {
+			for d := day; d <= last; d += 7 {
+				dowBits |= 1 << uint(d)
+			}
+		}
+		if 1<<uint(weekday)&s.DowLast > 0 {
+			dowBits |= 1 << uint(day+(last-day)/7*7)
 		}
+		for n := 0; n < 5; n++ {
+			if 1<<uint(n*7+weekday)&s.DowNth > 0 && day+n*7 <= last {
+				dowBits |= 1 << uint(day+n*7)
+			}
+		}
+	}
+
+	var days uint64
+	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
+		days = domBits & dowBits
+	} else {
+		days = domBits | dowBits
 	}
+	return days & monthDays
+}
 
-	for 1<<uint(t.Minute())&s.Minute == 0 {
-		if !added {
-			added = true
-			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+// daysIn returns the number of days of the given month.
+func daysIn(year int, month time.Month) int {
+	switch month {
+	case time.February:
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			return 29
 		}
-		t = t.Add(1 * time.Minute)
+		return 28
+	case time.April, time.June, time.September, time.November:
+		return 30
+	}
+	return 31
+}
 
-		if t.Minute() == 0 {
-			goto WRAP
+// nearestWeekday returns the weekday (Monday to Friday) nearest to the given
+// day of the month, without leaving the month.
+func nearestWeekday(year int, month time.Month, day int) int {
+	switch time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == daysIn(year, month) {
+			return day - 2
 		}
+		return day + 1
 	}
+	return day
+}
 
-	for 1<<uint(t.Second())&s.Second == 0 {
-		if !added {
-			added = true
-			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+// String returns the canonical spec of the schedule, in the default layout of
+// Parse: "second minute hour dom month dow [year]", prefixed by its time zone if
+// any. Parsing it gives back an equivalent schedule, but with the Horizon and
+// the DST policy of the parser: they are not encoded.
+func (s *SpecSchedule) String() string {
+	domItems := formatBits(s.Dom, dom)
+	for n := uint(0); n < 64; n++ {
+		if 1<<n&s.DomLast > 0 {
+			if n == 0 {
+				domItems = append(domItems, "L")
+			} else {
+				domItems = append(domItems, fmt.Sprintf("L-%d", n))
+			}
 		}
-		t = t.Add(1 * time.Second)
+	}
+	for n := uint(0); n < 64; n++ {
+		if 1<<n&s.DomWeekday > 0 {
+			if n == 0 {
+				domItems = append(domItems, "LW")
+			} else {
+				domItems = append(domItems, fmt.Sprintf("%dW", n))
+			}
+		}
+	}
 
-		if t.Second() == 0 {
-			goto WRAP
+	dowItems := formatBits(s.Dow, dow)
+	for d := uint(0); d < 7; d++ {
+		if 1<<d&s.DowLast > 0 {
+			dowItems = append(dowItems, fmt.Sprintf("%dL", d))
+		}
+	}
+	for i := uint(0); i < 35; i++ {
+		if 1<<i&s.DowNth > 0 {
+			dowItems = append(dowItems, fmt.Sprintf("%d#%d", i%7, i/7+1))
 		}
 	}
 
-	return t
+	fields := []string{
+		strings.Join(formatBits(s.Second, seconds), ","),
+		strings.Join(formatBits(s.Minute, minutes), ","),
+		strings.Join(formatBits(s.Hour, hours), ","),
+		strings.Join(domItems, ","),
+		strings.Join(formatBits(s.Month, months), ","),
+		strings.Join(dowItems, ","),
+	}
+	if s.Years != nil {
+		fields = append(fields, strings.Join(formatYears(s.Years, "-"), ","))
+	}
+
+	spec := strings.Join(fields, " ")
+	if s.Location != nil {
+		spec = "CRON_TZ=" + s.Location.String() + " " + spec
+	}
+	return spec
+}
+
+// MarshalText implements encoding.TextMarshaler, using String.
+func (s *SpecSchedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
 }
 
-// dayMatches returns true if the schedule's day-of-week and day-of-month
-// restrictions are satisfied by the given time.
-func dayMatches(s *SpecSchedule, t time.Time) bool {
-	var (
-		domMatch bool = 1<<uint(t.Day())&s.Dom > 0
-		dowMatch bool = 1<<uint(t.Weekday())&s.Dow > 0
-	)
+// UnmarshalText implements encoding.TextUnmarshaler, using Parse: the Horizon
+// and the DST policy are the default ones.
+func (s *SpecSchedule) UnmarshalText(text []byte) error {
+	schedule, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	spec, ok := schedule.(*SpecSchedule)
+	if !ok {
+		return unexpectedScheduleError(text, schedule)
+	}
+	*s = *spec
+	return nil
+}
 
-	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
-		return domMatch && dowMatch
+// formatBits returns the items of a field representing the given bits: a star,
+// possibly with a step, if the star bit is set, followed by values and ranges.
+func formatBits(bits uint64, r bounds) []string {
+	var items []string
+	if bits&starBit > 0 && bits&(1<<r.min) > 0 {
+		// Find the smallest step whose values are all set, there is always one
+		// as the largest step only gives the minimum.
+		step := uint(1)
+		for getBits(r.min, r.max, step)&^bits != 0 {
+			step++
+		}
+		if step == 1 {
+			items = append(items, "*")
+		} else {
+			items = append(items, "*/"+strconv.Itoa(int(step)))
+		}
+		bits &^= getBits(r.min, r.max, step)
 	}
-	return domMatch || dowMatch
+	return append(items, formatRanges(bits&^starBit, r.min, r.max, "-", func(v uint) string {
+		return strconv.Itoa(int(v))
+	})...)
+}
+
+// formatRanges returns the values of the given bits between min and max, with
+// the runs of more than 2 consecutive values as ranges.
+func formatRanges(bits uint64, min, max uint, sep string, format func(uint) string) []string {
+	var items []string
+	for v := min; v <= max; v++ {
+		if bits&(1<<v) == 0 {
+			continue
+		}
+		end := v
+		for end < max && bits&(1<<(end+1)) > 0 {
+			end++
+		}
+		switch end - v {
+		case 0:
+			items = append(items, format(v))
+		case 1:
+			items = append(items, format(v), format(end))
+		default:
+			items = append(items, format(v)+sep+format(end))
+		}
+		v = end
+	}
+	return items
+}
+
+// formatYears returns the given sorted years, with the runs of more than 2
+// consecutive years as ranges.
+func formatYears(years []int, sep string) []string {
+	var items []string
+	for i := 0; i < len(years); i++ {
+		j := i
+		for j+1 < len(years) && years[j+1] == years[j]+1 {
+			j++
+		}
+		switch j - i {
+		case 0:
+			items = append(items, strconv.Itoa(years[i]))
+		case 1:
+			items = append(items, strconv.Itoa(years[i]), strconv.Itoa(years[j]))
+		default:
+			items = append(items, strconv.Itoa(years[i])+sep+strconv.Itoa(years[j]))
+		}
+		i = j
+	}
+	return items
+}
+
+// unparsableError is returned by the MarshalText methods of the schedules whose
+// String doesn't give them back when parsed.
+func unparsableError(schedule fmt.Stringer) error {
+	return fmt.Errorf("%q (%T) can't be parsed back", schedule.String(), schedule)
+}
+
+// unexpectedScheduleError is returned by the UnmarshalText methods when the
+// text describes another kind of schedule.
+func unexpectedScheduleError(text []byte, schedule Schedule) error {
+	return fmt.Errorf("%q describes a schedule of another type (%T)", text, schedule)
 }
@@ -1,6 +1,10 @@
 package etcdcron
 
 import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -116,9 +120,45 @@ func TestNext(t *testing.T) {
 		{"2012-11-04T00:00:00-0400", "0 30 2 04 Nov ?", "2012-11-04T02:30:00-0500"},
 		{"2012-11-04T01:45:00-0400", "0 30 1 04 Nov ?", "2012-11-04T01:30:00-0500"},
 
-		// Unsatisfiable
-		{"Mon Jul 9 23:35 2012", "0 0 0 30 Feb ?", ""},
-		{"Mon Jul 9 23:35 2012", "0 0 0 31 Apr ?", ""},
+		// Last day of month
+		{"Mon Jul 9 23:35 2012", "0 0 0 L * ?", "Tue Jul 31 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 L-2 * ?", "Sun Jul 29 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 L Feb ?", "Thu Feb 28 00:00 2013"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1,L * ?", "Tue Jul 31 00:00 2012"},
+
+		// Nearest weekday, without leaving the month
+		{"Mon Jul 9 23:35 2012", "0 0 0 15W * ?", "Mon Jul 16 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 15W Dec ?", "Fri Dec 14 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1W Sep ?", "Mon Sep 3 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 LW Sep ?", "Fri Sep 28 00:00 2012"},
+
+		// Last and n-th day of week of the month
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 5L", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * FRIL", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 2#3", "Tue Jul 17 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * MON#2", "Mon Aug 13 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 1#5", "Mon Jul 30 00:00 2012"},
+
+		// Modifiers of both day fields: only one needs to match
+		{"Mon Jul 9 23:35 2012", "0 0 0 L * 2#3", "Tue Jul 17 00:00 2012"},
+
+		// Wrap-around ranges
+		{"Mon Jul 9 21:35 2012", "0 0 22-2 * * *", "Mon Jul 9 22:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 22-2 * * *", "Tue Jul 10 00:00 2012"},
+		{"Tue Jul 10 02:00 2012", "0 0 22-2 * * *", "Tue Jul 10 22:00 2012"},
+		{"Tue Jul 10 00:00 2012", "0 0 22-4/2 * * *", "Tue Jul 10 02:00 2012"},
+		{"Tue Jul 10 04:00 2012", "0 0 22-4/2 * * *", "Tue Jul 10 22:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 50-10/10 * * * *", "Mon Jul 9 23:50 2012"},
+		{"Tue Jul 10 00:00 2012", "0 0 0 * * FRI-MON", "Fri Jul 13 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 NOV-FEB ?", "Thu Nov 1 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 30-2 * ?", "Mon Jul 30 00:00 2012"},
+
+		// Years, even beyond the default horizon
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 * 2027-2030", "Fri Jan 1 00:00 2027"},
+		{"Fri Jan 1 00:00 2027", "0 0 0 1 1 * 2027-2030", "Sat Jan 1 00:00 2028"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 * 2012,2014", "Wed Jan 1 00:00 2014"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? Feb 1#5 2040-2050", "Mon Feb 29 00:00 2044"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 * 2010,2011", ""},
 	}
 
 	for _, c := range runs {
@@ -133,6 +173,160 @@ func TestNext(t *testing.T) {
 			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
 		}
 	}
+
+	// Unsatisfiable, which Parse rejects.
+	for spec, sched := range unsatisfiableSchedules() {
+		if actual := sched.Next(getTime("Mon Jul 9 23:35 2012")); !actual.IsZero() {
+			t.Errorf("\"%s\": (expected) zero time != %v (actual)", spec, actual)
+		}
+	}
+}
+
+// unsatisfiableSchedules returns schedules which can never be activated, by
+// spec, built without Parse which rejects them.
+func unsatisfiableSchedules() map[string]*SpecSchedule {
+	return map[string]*SpecSchedule{
+		"0 0 0 30 Feb ?": {Second: 1, Minute: 1, Hour: 1, Dom: 1 << 30, Month: 1 << time.February, Dow: all(dow)},
+		"0 0 0 31 Apr ?": {Second: 1, Minute: 1, Hour: 1, Dom: 1 << 31, Month: 1 << time.April, Dow: all(dow)},
+	}
+}
+
+func TestPrev(t *testing.T) {
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Simple cases
+		{"Mon Jul 9 15:00 2012", "0 0/15 * * *", "Mon Jul 9 14:45 2012"},
+		{"Mon Jul 9 15:00:01 2012", "0 0/15 * * *", "Mon Jul 9 15:00 2012"},
+		{"Mon Jul 9 14:59:59 2012", "0 0/15 * * *", "Mon Jul 9 14:45 2012"},
+
+		// Wrap around hours
+		{"Mon Jul 9 16:10 2012", "0 20-35/15 * * *", "Mon Jul 9 15:35 2012"},
+
+		// Wrap around days
+		{"Tue Jul 10 00:00 2012", "0 */15 * * *", "Mon Jul 9 23:45 2012"},
+		{"Tue Jul 10 00:10:05 2012", "15/35 20-35/15 * * *", "Mon Jul 9 23:35:50 2012"},
+		{"Tue Jul 10 01:00 2012", "15/35 20-35/15 10-12 * *", "Mon Jul 9 12:35:50 2012"},
+		{"Thu Jul 12 00:00 2012", "0 0 0 */2 * *", "Wed Jul 11 00:00 2012"},
+
+		// Wrap around months and years
+		{"Wed Aug 1 00:00 2012", "0 0 12 L * *", "Tue Jul 31 12:00 2012"},
+		{"Mon Jan 7 00:00 2013", "0 0 0 1 Jun,Dec ?", "Sat Dec 1 00:00 2012"},
+		{"Mon Jan 7 00:00 2013", "0 0 0 29 Feb ?", "Wed Feb 29 00:00 2012"},
+		{"Mon Jan 7 00:00 2013", "0 0 0 ? * 5#3", "Fri Dec 21 00:00 2012"},
+		{"Mon Jan 7 00:00 2013", "0 0 0 * * * 2010-2011", "Sat Dec 31 00:00 2011"},
+
+		// No activation time left
+		{"Mon Jul 9 23:35 2012", "0 0 0 * * * 2013", ""},
+	}
+
+	for _, c := range runs {
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.(*SpecSchedule).Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+
+	// Unsatisfiable, which Parse rejects.
+	for spec, sched := range unsatisfiableSchedules() {
+		if actual := sched.Prev(getTime("Mon Jul 9 23:35 2012")); !actual.IsZero() {
+			t.Errorf("\"%s\": (expected) zero time != %v (actual)", spec, actual)
+		}
+	}
+}
+
+// TestPrevNext checks that Prev returns an activation time, and that there is
+// none between it and the given time.
+func TestPrevNext(t *testing.T) {
+	specs := []string{
+		"* * * * * *", "0 */7 * * * *", "30 15 3 * * 1-5", "0 0 0 L-2,15W * *",
+		"0 0 12 ? * 2#2,6L", "0 0 9 1 */3 ?", "CRON_TZ=America/New_York 0 30 1,2,3 * * *",
+	}
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for _, spec := range specs {
+		sched, err := Parse(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := sched.(*SpecSchedule)
+		for i := 0; i < 500; i++ {
+			now := start.Add(time.Duration(i)*17*time.Hour + time.Duration(i)*time.Millisecond*333)
+			prev := s.Prev(now)
+			if !prev.Before(now) || !s.Next(prev.Add(-time.Second)).Equal(prev) || s.Next(prev).Before(now) {
+				t.Fatalf("%s, %v: unexpected previous activation %v, next activation %v", spec, now, prev, s.Next(prev))
+			}
+		}
+	}
+}
+
+func TestNextWithLocation(t *testing.T) {
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// The time given to Next is in UTC, the schedule is evaluated in Paris.
+		{"2012-07-09T14:45:00+0000", "CRON_TZ=Europe/Paris 0 0 18 * * *", "2012-07-09T16:00:00+0000"},
+		{"2012-07-09T16:00:00+0000", "TZ=Europe/Paris 0 0 18 * * *", "2012-07-10T16:00:00+0000"},
+		{"2012-12-09T14:45:00+0000", "CRON_TZ=Europe/Paris 0 0 18 * * *", "2012-12-09T17:00:00+0000"},
+
+		// Day boundaries are the ones of the schedule's time zone.
+		{"2012-07-09T23:30:00+0000", "CRON_TZ=Asia/Tokyo 0 0 9 10 * ?", "2012-07-10T00:00:00+0000"},
+
+		// Without time zone, the location of the given time is used.
+		{"2012-07-09T14:45:00+0000", "0 0 18 * * *", "2012-07-09T18:00:00+0000"},
+	}
+
+	for _, c := range runs {
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		now, _ := time.Parse("2006-01-02T15:04:05-0700", c.time)
+		expected, _ := time.Parse("2006-01-02T15:04:05-0700", c.expected)
+		actual := sched.Next(now)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+		if actual.Location() != now.Location() {
+			t.Errorf("%s, \"%s\": (expected) location %v != %v (actual)", c.time, c.spec, now.Location(), actual.Location())
+		}
+	}
+}
+
+func TestHorizon(t *testing.T) {
+	// The fifth Monday of February only happens in 2016, 2044 and 2072.
+	sched, err := Parse("0 0 0 ? Feb 1#5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := sched.(*SpecSchedule)
+
+	runs := []struct {
+		horizon  int
+		expected string
+	}{
+		{0, ""},
+		{DefaultHorizon, ""},
+		{26, ""},
+		{27, "Mon Feb 29 00:00 2044"},
+	}
+
+	for _, c := range runs {
+		spec.Horizon = c.horizon
+		actual := spec.Next(getTime("Sun Jan 1 00:00 2017"))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("horizon %d: (expected) %v != %v (actual)", c.horizon, expected, actual)
+		}
+	}
 }
 
 func TestErrors(t *testing.T) {
@@ -141,6 +335,18 @@ func TestErrors(t *testing.T) {
 		"60 0 * * *",
 		"0 60 * * *",
 		"0 0 * * XYZ",
+		"0 0 0 L-31 * ?",
+		"0 0 0 32W * ?",
+		"0 0 0 ? * 7L",
+		"0 0 0 ? * 1#6",
+		"0 0 0 ? * 1#2#3",
+		"0 0 0 ? * L#2",
+		"0 0 L * * ?",
+		"0 0 0 1 1 * 1969",
+		"0 0 0 1 1 * 2100",
+		"0 0 0 1 1 * 2030-2027",
+		"0 0 0 1 1 * 2027 *",
+		"*/0 * * * * *",
 	}
 	for _, spec := range invalidSpecs {
 		_, err := Parse(spec)
@@ -150,6 +356,139 @@ func TestErrors(t *testing.T) {
 	}
 }
 
+func TestScheduleString(t *testing.T) {
+	runs := []struct {
+		spec, expected string
+	}{
+		{"* * * * * *", "* * * * * *"},
+		{"0 0/15 * * *", "0 0,15,30,45 * * * *"},
+		{"0 */15 * * * *", "0 */15 * * * *"},
+		{"15/35 20-35/15 1/2 */2 * *", "15,50 20,35 1,3,5,7,9,11,13,15,17,19,21,23 */2 * *"},
+		{"0 0 9-17 * * MON-FRI", "0 0 9-17 * * 1-5"},
+		{"0 0 0 1,15,L * ?", "0 0 0 1,15,L * *"},
+		{"0 0 0 L-3,LW,15W * ?", "0 0 0 L-3,LW,15W * *"},
+		{"0 0 0 ? * 5L,TUE#3", "0 0 0 * * 5L,2#3"},
+		{"0 0 0 1 1 * 2027-2030,2035", "0 0 0 1 1 * 2027-2030,2035"},
+		{"CRON_TZ=Europe/Paris 0 0 6 * * *", "CRON_TZ=Europe/Paris 0 0 6 * * *"},
+		{"@daily", "0 0 0 * * *"},
+		{"@weekly", "0 0 0 * * 0"},
+		{"@every 1h30m", "@every 1h30m0s"},
+		{"@every 1h offset 75m", "@every 1h0m0s offset 15m0s"},
+		{"@every 24h from 2026-01-05T09:00:00+01:00", "@every 24h0m0s offset 8h0m0s"},
+		{"@at 2026-11-01T03:00:00Z", "@at 2026-11-01T03:00:00Z"},
+		{"R5/2026-11-01T03:00:00Z/PT90M", "R5/2026-11-01T03:00:00Z/PT1H30M"},
+		{"R/2026-11-01T03:00:00+01:00/P1D", "R/2026-11-01T03:00:00+01:00/PT24H"},
+		{"RRULE:FREQ=DAILY;BYHOUR=9", "DTSTART:19700101T000000 RRULE:FREQ=DAILY;BYHOUR=9"},
+		{
+			"DTSTART;TZID=Europe/Paris:20260105T090000 RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,-1TH;UNTIL=20270630T215959Z EXDATE:20260202T080000Z",
+			"DTSTART;TZID=Europe/Paris:20260105T090000 RRULE:FREQ=WEEKLY;INTERVAL=2;UNTIL=20270630T215959Z;BYDAY=MO,-1TH EXDATE;TZID=Europe/Paris:20260202T090000",
+		},
+		{
+			"CRON_TZ=Europe/Paris DTSTART:20260105T090000 RRULE:FREQ=DAILY;UNTIL=20270630T090000 EXDATE:20260107T090000",
+			"DTSTART;TZID=Europe/Paris:20260105T090000 RRULE:FREQ=DAILY;UNTIL=20270630T070000Z EXDATE;TZID=Europe/Paris:20260107T090000",
+		},
+		{"DTSTART:20260105T090000Z RRULE:FREQ=MONTHLY;COUNT=3;WKST=SU;BYMONTHDAY=1,-1", "DTSTART:20260105T090000Z RRULE:FREQ=MONTHLY;COUNT=3;WKST=SU;BYMONTHDAY=1,-1"},
+		{"OnCalendar=Mon..Fri *-*-* 09:00:00", "OnCalendar=Mon..Fri *-*-* 09:00:00"},
+		{"OnCalendar=weekly Europe/Paris", "OnCalendar=Mon *-*-* 00:00:00 Europe/Paris"},
+		{"OnCalendar=2027..2030-02~03,01 *:0/15", "OnCalendar=2027..2030-02~01,03 *:00,15,30,45:00"},
+		{"OnCalendar=Sat,Sun *-*-1..5 12:00", "OnCalendar=Sun,Sat *-*-01..05 12:00:00"},
+		{"cron(0 12 ? * MON-FRI *)", "CRON_TZ=UTC 0 0 12 * * 1-5"},
+		{"cron(15 10 ? * 6L 2026-2027)", "CRON_TZ=UTC 0 15 10 * * 5L 2026,2027"},
+		{"rate(5 minutes)", "@every 5m0s"},
+		{"@random 2:00-4:00", "@random 02:00-04:00 daily"},
+		{"CRON_TZ=Europe/Paris @random 23:30-00:30:30 WEEKLY", "CRON_TZ=Europe/Paris @random 23:30-00:30:30 weekly"},
+		{`@random 02:00-04:00 key "backup"`, `@random 02:00-04:00 daily key "backup"`},
+		{"@sunset 48.8566 2.3522 30m", "@sunset 48.8566 2.3522 30m0s"},
+		{"@sunrise -33.86880 +151.2093 -1h", "@sunrise -33.8688 151.2093 -1h0m0s"},
+	}
+
+	from := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	for _, c := range runs {
+		s, err := Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		actual := s.(fmt.Stringer).String()
+		if actual != c.expected {
+			t.Errorf("%s: (expected) %q != %q (actual)", c.spec, c.expected, actual)
+			continue
+		}
+
+		// The canonical spec gives back the same schedule, also through JSON.
+		parsed, err := Parse(actual)
+		if err != nil {
+			t.Errorf("%s: %v", actual, err)
+			continue
+		}
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		if string(data) != fmt.Sprintf("%q", c.expected) {
+			t.Errorf("%s: (expected) %q != %s (actual JSON)", c.spec, c.expected, data)
+		}
+		typ := reflect.TypeOf(s)
+		target := reflect.New(typ)
+		if typ.Kind() == reflect.Ptr {
+			target.Elem().Set(reflect.New(typ.Elem()))
+		}
+		if err := json.Unmarshal(data, target.Interface()); err != nil {
+			t.Errorf("%s: %v", data, err)
+			continue
+		}
+		unmarshaled := target.Elem().Interface().(Schedule)
+
+		for _, other := range []Schedule{parsed, unmarshaled} {
+			a, b := from, from
+			for i := 0; i < 20 && !a.IsZero(); i++ {
+				a, b = s.Next(a), other.Next(b)
+				if !a.Equal(b) {
+					t.Errorf("%s: (expected) %v != %v (actual) for %T", c.spec, a, b, other)
+					break
+				}
+			}
+		}
+	}
+
+	var spec SpecSchedule
+	if err := spec.UnmarshalText([]byte("@every 1h")); err == nil {
+		t.Error("expected an error unmarshaling another type of schedule")
+	}
+}
+
+func TestScheduleMarshalTextErrors(t *testing.T) {
+	hourly := mustParse(t, "0 0 * * * *")
+	window := Window{Start: mustParse(t, "0 0 23 * * 0"), Duration: 2 * time.Hour}
+	anchor := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	// The schedules whose String can't be parsed back.
+	for _, s := range []Schedule{
+		Every(time.Hour),
+		Union(hourly, Every(time.Hour)),
+		Intersect(hourly, mustParse(t, "0 0 9 * * *")),
+		Except(hourly, window),
+		Within(hourly, window),
+		Jitter(hourly, time.Minute),
+		EveryPeriod(hourly, Weekly, 2, anchor),
+		OnBusinessDays(hourly, NewCalendar(), SkipHolidays),
+	} {
+		if data, err := json.Marshal(s); err == nil {
+			t.Errorf("%v: expected an error, got %s", s, data)
+		}
+	}
+
+	// An anchored interval is marshaled.
+	data, err := json.Marshal(EveryFrom(time.Hour, anchor.Add(15*time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `"@every 1h0m0s offset 15m0s"`; string(data) != expected {
+		t.Errorf("(expected) %s != %s (actual)", expected, data)
+	}
+}
+
 func getTime(value string) time.Time {
 	if value == "" {
 		return time.Time{}
@@ -171,3 +510,372 @@ func getTime(value string) time.Time {
 
 	return t
 }
+
+// TestSpecScanEquivalence checks that nextWall and prevWall, which jump to the
+// next values of the bit sets, give the same times as the incremental
+// algorithms, on random schedules.
+func TestSpecScanEquivalence(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	randomBits := func(b bounds, density int) uint64 {
+		var set uint64
+		for v := b.min; v <= b.max; v++ {
+			if r.Intn(density) == 0 {
+				set |= 1 << v
+			}
+		}
+		if set == 0 {
+			set = 1 << (b.min + uint(r.Intn(int(b.max-b.min+1))))
+		}
+		return set
+	}
+
+	for i := 0; i < 2000; i++ {
+		s := &SpecSchedule{
+			Second: randomBits(seconds, 1+r.Intn(60)),
+			Minute: randomBits(minutes, 1+r.Intn(60)),
+			Hour:   randomBits(hours, 1+r.Intn(24)),
+			Dom:    randomBits(dom, 1+r.Intn(31)),
+			Month:  randomBits(months, 1+r.Intn(12)),
+			Dow:    randomBits(dow, 1+r.Intn(7)),
+		}
+		switch r.Intn(6) {
+		case 0:
+			s.Dom |= starBit
+		case 1:
+			s.Dow |= starBit
+		case 2:
+			s.DomLast, s.DomWeekday = uint64(r.Intn(1<<4)), uint64(r.Intn(1<<4))<<uint(r.Intn(28))
+		case 3:
+			s.DowLast, s.DowNth = uint64(r.Intn(1<<7)), uint64(r.Int63n(1<<35))&^(uint64(r.Int63n(1<<35)))
+		case 4:
+			s.Years = []int{2024, 2027 + r.Intn(3)}
+		}
+
+		yearLimit := 2026 + r.Intn(4)
+		start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(r.Int63n(int64(3 * 365 * 24 * time.Hour))))
+		if r.Intn(2) == 0 {
+			start = start.Truncate(time.Second)
+		}
+		for next, j := start, 0; j < 5; j++ {
+			expected, actual := incrementalNextWall(s, next, yearLimit), s.nextWall(next, yearLimit)
+			if !actual.Equal(expected) {
+				t.Fatalf("%+v nextWall(%v): (expected) %v != %v (actual)", s, next, expected, actual)
+			}
+			if actual.IsZero() {
+				break
+			}
+			next = actual
+		}
+		for prev, j := start, 0; j < 5; j++ {
+			expected, actual := incrementalPrevWall(s, prev, yearLimit-4), s.prevWall(prev, yearLimit-4)
+			if !actual.Equal(expected) {
+				t.Fatalf("%+v prevWall(%v): (expected) %v != %v (actual)", s, prev, expected, actual)
+			}
+			if actual.IsZero() {
+				break
+			}
+			prev = actual
+		}
+	}
+}
+
+// benchmarkSpecs are sparse and dense schedules of the benchmarks.
+var benchmarkSpecs = []struct{ name, spec string }{
+	{"EverySecond", "* * * * * *"},
+	{"Hourly", "0 0 * * * *"},
+	{"Weekdays", "0 30 9 * * MON-FRI"},
+	{"LastWeekday", "0 0 18 LW * *"},
+	{"NthWeekday", "0 0 12 ? * TUE#3"},
+	{"LeapDay", "0 0 0 29 2 *"},
+	{"Years", "0 0 0 1 1 * 2030,2035"},
+	{"TimeZone", "CRON_TZ=Europe/Paris 0 30 2 * * *"},
+}
+
+func BenchmarkSpecScheduleNext(b *testing.B) {
+	start := time.Date(2026, time.October, 17, 10, 0, 0, 0, time.UTC)
+	for _, bm := range benchmarkSpecs {
+		s, err := Parse(bm.spec)
+		if err != nil {
+			b.Fatal(err)
+		}
+		s.(*SpecSchedule).Horizon = 10
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			t := start
+			for i := 0; i < b.N; i++ {
+				if t = s.Next(t); t.IsZero() {
+					t = start
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSpecSchedulePrev(b *testing.B) {
+	start := time.Date(2036, time.October, 17, 10, 0, 0, 0, time.UTC)
+	for _, bm := range benchmarkSpecs {
+		s, err := Parse(bm.spec)
+		if err != nil {
+			b.Fatal(err)
+		}
+		s.(*SpecSchedule).Horizon = 10
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			t := start
+			for i := 0; i < b.N; i++ {
+				if t = s.(*SpecSchedule).Prev(t); t.IsZero() {
+					t = start
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkNextWall compares nextWall with the incremental algorithm.
+func BenchmarkNextWall(b *testing.B) {
+	start := time.Date(2026, time.October, 17, 10, 0, 0, 0, time.UTC)
+	for _, bm := range benchmarkSpecs {
+		s, err := Parse(bm.spec)
+		if err != nil {
+			b.Fatal(err)
+		}
+		spec := s.(*SpecSchedule)
+		for _, algorithm := range []struct {
+			name     string
+			nextWall func(*SpecSchedule, time.Time, int) time.Time
+		}{
+			{"BitScan", (*SpecSchedule).nextWall},
+			{"Incremental", incrementalNextWall},
+		} {
+			b.Run(bm.name+"/"+algorithm.name, func(b *testing.B) {
+				t := start
+				for i := 0; i < b.N; i++ {
+					if t = algorithm.nextWall(spec, t, 2036); t.IsZero() {
+						t = start
+					}
+				}
+			})
+		}
+	}
+}
+
+// incrementalNextWall is the former implementation of nextWall, incrementing
+// the fields one unit at a time, used as a reference.
+func incrementalNextWall(s *SpecSchedule, t time.Time, yearLimit int) time.Time {
+	// General approach:
+	// For Month, Day, Hour, Minute, Second:
+	// Check if the time value matches.  If yes, continue to the next field.
+	// If the field doesn't match the schedule, then increment the field until it matches.
+	// While incrementing the field, a wrap-around brings it back to the beginning
+	// of the field list (since it is necessary to re-verify previous field
+	// values)
+
+	// Start at the earliest possible time (the upcoming second).
+	t = t.Add(1*time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+
+	// This flag indicates whether a field has been incremented.
+	added := false
+
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	// Find the first applicable year.
+	if year := s.nextYear(t.Year()); year != t.Year() {
+		if year == 0 {
+			return time.Time{}
+		}
+		added = true
+		t = time.Date(year, time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+
+	// Find the first applicable month.
+	// If it's this month, then do nothing.
+	for 1<<uint(t.Month())&s.Month == 0 {
+		// If we have to add a month, reset the other parts to 0.
+		if !added {
+			added = true
+			// Otherwise, set the date at the beginning (since the current time is irrelevant).
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		}
+		t = t.AddDate(0, 1, 0)
+
+		// Wrapped around.
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	// Now get a day in that month.
+	for !incrementalDayMatches(s, t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		}
+		t = t.AddDate(0, 0, 1)
+
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.Hour == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+		}
+		t = t.Add(1 * time.Hour)
+
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.Minute == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+		}
+		t = t.Add(1 * time.Minute)
+
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Second())&s.Second == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+		}
+		t = t.Add(1 * time.Second)
+
+		if t.Second() == 0 {
+			goto WRAP
+		}
+	}
+
+	return t
+}
+
+// incrementalPrevWall is the former implementation of prevWall.
+func incrementalPrevWall(s *SpecSchedule, t time.Time, yearLimit int) time.Time {
+	// Same approach as incrementalNextWall, the other way around: when a field doesn't
+	// match the schedule, the time is set to the last second of the previous
+	// value of the field, and a wrap-around brings it back to the beginning of
+	// the field list.
+
+	// Start at the latest possible time (the previous second).
+	if t.Nanosecond() > 0 {
+		t = t.Add(-time.Duration(t.Nanosecond()) * time.Nanosecond)
+	} else {
+		t = t.Add(-1 * time.Second)
+	}
+
+WRAP:
+	if t.Year() < yearLimit {
+		return time.Time{}
+	}
+
+	// Find the last applicable year.
+	if year := s.prevYear(t.Year()); year != t.Year() {
+		if year == 0 {
+			return time.Time{}
+		}
+		t = time.Date(year+1, time.January, 1, 0, 0, 0, 0, t.Location()).Add(-1 * time.Second)
+	}
+
+	for 1<<uint(t.Month())&s.Month == 0 {
+		t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).Add(-1 * time.Second)
+
+		// Wrapped around.
+		if t.Month() == time.December {
+			goto WRAP
+		}
+	}
+
+	for !incrementalDayMatches(s, t) {
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).Add(-1 * time.Second)
+
+		if t.Day() == daysIn(t.Year(), t.Month()) {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.Hour == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()).Add(-1 * time.Second)
+
+		if t.Hour() == 23 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.Minute == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location()).Add(-1 * time.Second)
+
+		if t.Minute() == 59 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Second())&s.Second == 0 {
+		t = t.Add(-1 * time.Second)
+
+		if t.Second() == 59 {
+			goto WRAP
+		}
+	}
+
+	return t
+}
+
+// incrementalDayMatches returns true if the schedule's day-of-week and day-of-month
+// restrictions are satisfied by the given time.
+func incrementalDayMatches(s *SpecSchedule, t time.Time) bool {
+	var (
+		domMatch bool = 1<<uint(t.Day())&s.Dom > 0 || incrementalDomModifiersMatch(s, t)
+		dowMatch bool = 1<<uint(t.Weekday())&s.Dow > 0 || incrementalDowModifiersMatch(s, t)
+	)
+
+	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// incrementalDomModifiersMatch returns true if one of the "L" and "W" modifiers of the
+// day-of-month field is satisfied by the given time.
+func incrementalDomModifiersMatch(s *SpecSchedule, t time.Time) bool {
+	if s.DomLast == 0 && s.DomWeekday == 0 {
+		return false
+	}
+
+	day, last := t.Day(), daysIn(t.Year(), t.Month())
+	if 1<<uint(last-day)&s.DomLast > 0 {
+		return true
+	}
+	if s.DomWeekday&1 > 0 && day == nearestWeekday(t.Year(), t.Month(), last) {
+		return true
+	}
+	for n := 1; n <= last; n++ {
+		if 1<<uint(n)&s.DomWeekday > 0 && day == nearestWeekday(t.Year(), t.Month(), n) {
+			return true
+		}
+	}
+	return false
+}
+
+// incrementalDowModifiersMatch returns true if one of the "L" and "#" modifiers of the
+// day-of-week field is satisfied by the given time.
+func incrementalDowModifiersMatch(s *SpecSchedule, t time.Time) bool {
+	if s.DowLast == 0 && s.DowNth == 0 {
+		return false
+	}
+
+	day, weekday := t.Day(), uint(t.Weekday())
+	if 1<<weekday&s.DowLast > 0 && day+7 > daysIn(t.Year(), t.Month()) {
+		return true
+	}
+	return 1<<(uint(day-1)/7*7+weekday)&s.DowNth > 0
+}
@@ -0,0 +1,171 @@
+package etcdcron
+
+import "time"
+
+// referenceTime is the time from which the intervals of the schedules which
+// aren't cron expressions are checked, so that the same schedule is accepted or
+// rejected whenever it is parsed.
+var referenceTime = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// check returns a *ParseError if the given schedule can never be activated, as
+// no second or no day matches its fields or, for the other kinds of schedules,
+// it has no activation time after referenceTime, or if it is activated less
+// often than allowed by WithMaxInterval.
+func (p *Parser) check(schedule Schedule) *ParseError {
+	var spec *SpecSchedule
+	switch s := schedule.(type) {
+	case *SpecSchedule:
+		spec = s
+	case *CalendarEventSchedule:
+		spec = &s.SpecSchedule
+	}
+	if spec != nil {
+		for _, field := range []struct {
+			name string
+			bits uint64
+		}{{"second", spec.Second}, {"minute", spec.Minute}, {"hour", spec.Hour}, {"month", spec.Month}} {
+			if field.bits&^starBit == 0 {
+				err := parseErrorf(ErrUnsatisfiable, "", "no value matches the %s field", field.name)
+				err.Field = field.name
+				return err
+			}
+		}
+		if !spec.satisfiable() {
+			return parseErrorf(ErrUnsatisfiable, "", "no day matches the day of month, month and day of week fields")
+		}
+	} else if schedule.Next(referenceTime).IsZero() {
+		return parseErrorf(ErrUnsatisfiable, "", "not activated after %s", referenceTime.Format(time.RFC3339))
+	}
+
+	if p.maxInterval == 0 {
+		return nil
+	}
+	if spec == nil {
+		return p.checkInterval(schedule, referenceTime)
+	}
+	// The intervals within a day are the same every year, but the ones
+	// between the days depend on the calendar of the year.
+	loc := spec.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	start := time.Date(spec.cycleYears()[0], time.January, 1, 0, 0, 0, 0, loc)
+	if err := p.checkInterval(schedule, start.Add(-time.Nanosecond)); err != nil {
+		return err
+	}
+	if from, to, ok := spec.longDayInterval(loc, p.maxInterval); ok {
+		// Such as February 29th on a Monday, beyond the horizon.
+		if to.IsZero() {
+			return parseErrorf(ErrTooRare, "", "not activated within %d years after %s", spec.horizon(), from.Format(time.RFC3339))
+		}
+		return parseErrorf(ErrTooRare, "", "not activated between %s and %s, more than %v apart", from.Format(time.RFC3339), to.Format(time.RFC3339), p.maxInterval)
+	}
+	return nil
+}
+
+// checkInterval returns a *ParseError if the given schedule is activated less
+// often than allowed by WithMaxInterval, within a year after the given time.
+func (p *Parser) checkInterval(schedule Schedule, t time.Time) *ParseError {
+	if from, to, ok := longInterval(schedule, t, p.maxInterval); ok {
+		return parseErrorf(ErrTooRare, "", "not activated between %s and %s, more than %v apart", from.Format(time.RFC3339), to.Format(time.RFC3339), p.maxInterval)
+	}
+	return nil
+}
+
+// satisfiable returns true if some day matches the day-of-month, month and
+// day-of-week fields of the schedule, in one of its years.
+func (s *SpecSchedule) satisfiable() bool {
+	for _, year := range s.cycleYears() {
+		for month := time.January; month <= time.December; month++ {
+			if 1<<uint(month)&s.Month > 0 && s.dayBits(year, month) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cycleYears returns the years of the schedule, or the years between 2001 and
+// 2028 if any year matches: the calendar of any year is the one of a year of
+// this 28-year cycle without skipped leap year.
+func (s *SpecSchedule) cycleYears() []int {
+	if s.Years != nil {
+		return s.Years
+	}
+	years := make([]int, 0, 28)
+	for year := 2001; year <= 2028; year++ {
+		years = append(years, year)
+	}
+	return years
+}
+
+// longDayInterval looks for two consecutive activation times of the schedule
+// more than max apart, on days more than a day apart, over its years or two
+// calendar cycles. The days are evaluated in the given location. The second
+// activation time is zero if it is beyond the horizon.
+func (s *SpecSchedule) longDayInterval(loc *time.Location, max time.Duration) (from, to time.Time, ok bool) {
+	years := s.cycleYears()
+	if s.Years == nil {
+		for _, year := range years {
+			years = append(years, year+28)
+		}
+	}
+
+	var last time.Time
+	for _, year := range years {
+		for month := time.January; month <= time.December; month++ {
+			if 1<<uint(month)&s.Month == 0 {
+				continue
+			}
+			days := s.dayBits(year, month)
+			for day := 1; day <= 31; day++ {
+				if 1<<uint(day)&days == 0 {
+					continue
+				}
+				d := time.Date(year, month, day, 0, 0, 0, 0, loc)
+				if !last.IsZero() && !last.AddDate(0, 0, 1).Equal(d) {
+					if from = s.Prev(d); from.IsZero() {
+						from = last
+					}
+					if to = s.Next(from); to.IsZero() || to.Sub(from) > max {
+						return from, to, true
+					}
+				}
+				last = d
+			}
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// longInterval looks for two consecutive activation times of the schedule
+// more than max apart, within a year and max after the first activation time
+// following the given time. Schedules with no activation time, or exhausted,
+// have no such interval.
+func longInterval(schedule Schedule, t time.Time, max time.Duration) (from, to time.Time, ok bool) {
+	prev, hasPrev := schedule.(interface{ Prev(time.Time) time.Time })
+
+	activation := schedule.Next(t)
+	if activation.IsZero() {
+		return time.Time{}, time.Time{}, false
+	}
+	end := activation.AddDate(1, 0, 0).Add(max)
+	for step := 0; step < maxCombinatorSteps && activation.Before(end); step++ {
+		next := schedule.Next(activation)
+		if next.IsZero() {
+			break
+		}
+		if next.Sub(activation) > max {
+			return activation, next, true
+		}
+		// The intervals between the activation times up to max later are
+		// shorter than max.
+		if hasPrev {
+			if last := prev.Prev(activation.Add(max + time.Nanosecond)); last.After(next) {
+				next = last
+			}
+		}
+		activation = next
+	}
+	return time.Time{}, time.Time{}, false
+}
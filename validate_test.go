@@ -0,0 +1,121 @@
+package etcdcron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseUnsatisfiable(t *testing.T) {
+	tests := []struct {
+		spec        string
+		satisfiable bool
+	}{
+		{"0 0 0 30 Feb ?", false},
+		{"0 0 0 31 Apr,Jun ?", false},
+		{"0 0 0 29 Feb ? 2027", false},
+		{"0 0 0 ? Feb 1#5 2025-2027", false},
+		{"0 0 0 L-30 Feb ?", false},
+		{"OnCalendar=*-02-30", false},
+		{"0 0 0 30 Feb MON", true},
+		{"0 0 0 29 Feb ?", true},
+		{"0 0 0 ? Feb 1#5", true},
+		{"0 0 0 31 Apr,May ?", true},
+		{"0 0 0 1 1 * 2012", true},
+
+		// The other kinds of schedules must be activated after 2001.
+		{"RRULE:FREQ=MONTHLY;BYMONTH=2;BYMONTHDAY=30", false},
+		{"DTSTART:19970902T090000 RRULE:FREQ=DAILY;COUNT=10", false},
+		{"DTSTART:19970902T090000 RRULE:FREQ=DAILY;UNTIL=20001231T000000", false},
+		{"R5/1999-11-01T03:00:00Z/PT1H", false},
+		{"@at 1999-11-01T03:00:00Z", false},
+		{"@sunrise 90 0", false},
+		{"RRULE:FREQ=MONTHLY;BYMONTHDAY=30", true},
+		{"DTSTART:19970902T090000 RRULE:FREQ=DAILY;COUNT=2000", true},
+		{"R/1999-11-01T03:00:00Z/PT1H", true},
+		{"@at 2026-11-01T03:00:00Z", true},
+		{"@sunrise 89 0", true},
+		{"@every 1h", true},
+		{"@random 02:00-04:00", true},
+	}
+
+	for _, c := range tests {
+		_, err := Parse(c.spec)
+		if c.satisfiable && err != nil {
+			t.Errorf("%q: unexpected error %v", c.spec, err)
+		}
+		if !c.satisfiable && !errors.Is(err, ErrUnsatisfiable) {
+			t.Errorf("%q: (expected) ErrUnsatisfiable != %v (actual)", c.spec, err)
+		}
+	}
+
+	// Fields with no value, which can't be parsed.
+	for _, field := range []string{"second", "minute", "hour", "month"} {
+		s := &SpecSchedule{Second: 1, Minute: 1, Hour: 1, Dom: all(dom), Month: all(months), Dow: all(dow)}
+		switch field {
+		case "second":
+			s.Second = starBit
+		case "minute":
+			s.Minute = 0
+		case "hour":
+			s.Hour = 0
+		case "month":
+			s.Month = 0
+		}
+		err := defaultParser.check(s)
+		if !errors.Is(err, ErrUnsatisfiable) || err.Field != field {
+			t.Errorf("%s: (expected) ErrUnsatisfiable != %v (actual)", field, err)
+		}
+	}
+
+	// Such schedules can still be built, and are never activated.
+	s := &SpecSchedule{Second: 1, Minute: 1, Hour: 1, Dom: 1 << 30, Month: 1 << 2, Dow: all(dow)}
+	if next := s.Next(getTime("Mon Jul 9 23:35 2012")); !next.IsZero() {
+		t.Errorf("(expected) the zero time != %v (actual)", next)
+	}
+	if prev := s.Prev(getTime("Mon Jul 9 23:35 2012")); !prev.IsZero() {
+		t.Errorf("(expected) the zero time != %v (actual)", prev)
+	}
+}
+
+func TestParseMaxInterval(t *testing.T) {
+	tests := []struct {
+		spec    string
+		max     time.Duration
+		tooRare bool
+	}{
+		{"0 0 9 * * *", 48 * time.Hour, false},
+		{"0 0 9 * * MON-FRI", 48 * time.Hour, true},
+		{"0 0 9 * * MON-FRI", 72 * time.Hour, false},
+		{"0 */15 9-17 * * *", 16 * time.Hour, false},
+		{"0 */15 9-17 * * *", 15 * time.Hour, true},
+		{"* * * * * *", time.Second, false},
+		{"0 0 0 * 1-11 *", 31 * 24 * time.Hour, true},
+		{"0 0 0 ? Feb 1#5", 2 * 366 * 24 * time.Hour, true},
+		{"@every 1h", time.Hour, false},
+		{"@every 72h", 48 * time.Hour, true},
+		{"RRULE:FREQ=WEEKLY;BYDAY=MO,TH", 4 * 24 * time.Hour, false},
+		{"RRULE:FREQ=WEEKLY;BYDAY=MO,TH", 3 * 24 * time.Hour, true},
+		{"@at 2012-07-09T23:35:00Z", time.Hour, false},
+
+		// The longest interval between two Fridays the 13th is 14 months, but
+		// most years don't contain it.
+		{"OnCalendar=Fri *-*-13", 400 * 24 * time.Hour, true},
+		{"OnCalendar=Fri *-*-13", 430 * 24 * time.Hour, false},
+		{"0 0 0 1 1 * 2012", time.Hour, false},
+	}
+
+	for _, c := range tests {
+		parser, err := NewParser(WithMaxInterval(c.max))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = parser.Parse(c.spec)
+		if c.tooRare && !errors.Is(err, ErrTooRare) {
+			t.Errorf("%q, %v: (expected) ErrTooRare != %v (actual)", c.spec, c.max, err)
+		}
+		if !c.tooRare && err != nil {
+			t.Errorf("%q, %v: unexpected error %v", c.spec, c.max, err)
+		}
+	}
+}